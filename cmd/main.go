@@ -2,11 +2,13 @@ package main
 
 import (
 	"flag"
-	"fmt"
 	"log"
+	"os"
 
 	"github.com/ajtroup1/GoDoc/internal/generator"
+	"github.com/ajtroup1/GoDoc/internal/models"
 	"github.com/ajtroup1/GoDoc/internal/parser"
+	"github.com/ajtroup1/GoDoc/internal/report"
 	"github.com/ajtroup1/GoDoc/utils"
 )
 
@@ -19,6 +21,9 @@ const (
 
 func main() {
 	task := flag.String("task", "", Red+"Specify the task to run (e.g., save, gen)"+Reset)
+	force := flag.Bool("force", false, "Ignore the save cache and read every file again")
+	reportPath := flag.String("report", "", "Also write every problem to this file (use - for stdout)")
+	reportFormat := flag.String("report-format", "", "Report format: sarif, junit or github (inferred from the -report file extension if empty)")
 	flag.Parse()
 
 	// Retreive settings
@@ -27,42 +32,79 @@ func main() {
 		log.Fatalf(Red+"Error reading settings: %v"+Reset, err)
 	}
 
+	// Check the report format up front, rather than after all the work is done
+	if *reportPath != "" {
+		if _, err := report.ResolveFormat(*reportPath, *reportFormat); err != nil {
+			log.Fatalf(Red+"Error reading flags: %v"+Reset, err)
+		}
+	}
+
 	// Execute the appropriate task based on the flag
+	var diagnostics []models.Diagnostic
 	switch *task {
 	case "save":
-		save(settings)
+		diagnostics = save(settings, *force)
 	case "gen":
-		gen(settings)
+		diagnostics = gen(settings)
 	default:
-		fmt.Println(Red + "Unknown task. Please specify 'save' or 'gen'." + Reset)
+		log.Println(Red + "Unknown task. Please specify 'save' or 'gen'." + Reset)
+		return
+	}
+
+	// Log any problems with the documentation to the user
+	logDiagnostics(diagnostics)
+
+	// Save them for CI too, if asked
+	if *reportPath != "" {
+		if err := report.Write(*reportPath, *reportFormat, *task, diagnostics); err != nil {
+			log.Fatalf(Red+"Error writing report: %v"+Reset, err)
+		}
+	}
+
+	// Fail so CI can gate on documentation errors
+	if code := exitCode(diagnostics); code != 0 {
+		os.Exit(code)
 	}
-	fmt.Print("" + Reset) // Prevents compiler error if all fmt are disabled
+}
 
+// exitCode is the status a task exits with: 1 if any diagnostic is an error, otherwise 0.
+// Warnings and info never fail a task.
+func exitCode(diagnostics []models.Diagnostic) int {
+	if models.HasErrors(diagnostics) {
+		return 1
+	}
+	return 0
 }
 
-func save(settings *utils.SettingManager) {
+func save(settings *utils.SettingManager, force bool) []models.Diagnostic {
 	// Parse the src code into the heirarchal structure
 	parser := parser.New(settings.Settings)
+	parser.Force = force
 	parser.ParseProject()
-
-	// If there were parsing errors, log them to the user
-	if len(parser.Errors) > 0 {
-		for _, err := range parser.Errors {
-			log.Printf(Red+"Parsing error: %v\n"+Reset, err)
-		}
-	}
+	return parser.Diagnostics
 }
 
-func gen(settings *utils.SettingManager) {
+func gen(settings *utils.SettingManager) []models.Diagnostic {
 	// Generate the program's documentation from the heirarchal structure
+	// In the end application, there should never be genereation errors
 	generator := generator.New(settings.Settings)
 	generator.GenerateDocs()
+	return generator.Diagnostics
+}
 
-	// If there were generation errors, log them to the user
-	// In the end application, there should never be genereation errors
-	if len(generator.Errors) > 0 {
-		for _, err := range generator.Errors {
-			log.Printf(Red+"Generation error: %v\n"+Reset, err)
+// logDiagnostics prints each diagnostic in the color of its severity, along with any suggested fix
+func logDiagnostics(diagnostics []models.Diagnostic) {
+	for _, diag := range diagnostics {
+		color := Green
+		if diag.Severity == models.SeverityError {
+			color = Red
+		} else if diag.Severity == models.SeverityWarning {
+			color = Yellow
+		}
+		if diag.Fix != "" {
+			log.Printf(color+"%v\n    fix: %s"+Reset, diag, diag.Fix)
+		} else {
+			log.Printf(color+"%v"+Reset, diag)
 		}
 	}
 }
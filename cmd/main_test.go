@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		diagnostics []models.Diagnostic
+		want        int
+	}{
+		{name: "nothing reported", want: 0},
+		{name: "info", diagnostics: []models.Diagnostic{models.Infof(models.CodeUndocumented, "undocumented")}, want: 0},
+		{name: "warnings only", diagnostics: []models.Diagnostic{models.Warningf(models.CodeMismatch, "mismatch"), models.Infof(models.CodeUndocumented, "undocumented")}, want: 0},
+		{name: "one error", diagnostics: []models.Diagnostic{models.Errorf(models.CodeNoDeclaration, "missing")}, want: 1},
+		{name: "error among warnings", diagnostics: []models.Diagnostic{models.Warningf(models.CodeMismatch, "mismatch"), models.Errorf(models.CodeIO, "unreadable"), models.Infof(models.CodeUndocumented, "undocumented")}, want: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := exitCode(test.diagnostics); got != test.want {
+				t.Errorf("exitCode(%v) = %d, want %d", test.diagnostics, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package generator
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+	"github.com/ajtroup1/GoDoc/internal/parser"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// exampleSettings mirrors the repo's settings.json, which the checked-in docs are generated with
+func exampleSettings() models.Settings {
+	return models.Settings{
+		ProjectName:  "Example",
+		ProjectDesc:  "This is an example documenation for GoDoc.",
+		DocGenFormat: models.Formats{"markdown"},
+		IncludeTests: true,
+		TOCDepth:     tocDepthOf(2),
+	}
+}
+
+func tocDepthOf(depth int) *int {
+	return &depth
+}
+
+// saveFixture runs a save over the test/ example project and returns the modules it stored.
+// The test then runs in a temporary directory holding godoc_output.json, so GenerateDocs reads the fixture.
+func saveFixture(t *testing.T, settings models.Settings) []models.Module {
+	t.Helper()
+	fixture, err := filepath.Abs(filepath.Join("..", "..", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdirTemp(t)
+
+	settings.ProjectPath = fixture
+	p := parser.New(settings)
+	p.Force = true
+	p.ParseProject()
+	if models.HasErrors(p.Diagnostics) {
+		t.Fatalf("saving the fixture failed: %v", p.Diagnostics)
+	}
+	return p.Modules
+}
+
+// chdirTemp moves the test into a temporary directory for as long as it runs
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// generate runs GenerateDocs into a fresh DocGenPath and returns the generator and the directory
+func generate(t *testing.T, settings models.Settings) (*Generator, string) {
+	t.Helper()
+	settings.DocGenPath = t.TempDir()
+	g := New(settings)
+	g.GenerateDocs()
+	return g, settings.DocGenPath
+}
+
+// checkGolden compares output with testdata/name, or rewrites it when the tests run with -update
+func checkGolden(t *testing.T, name string, output []byte) {
+	t.Helper()
+	path := filepath.Join(testdataDir, name)
+	if *update {
+		if err := os.WriteFile(path, output, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != string(want) {
+		gotLines, wantLines := strings.Split(string(output), "\n"), strings.Split(string(want), "\n")
+		for i := range min(len(gotLines), len(wantLines)) {
+			if gotLines[i] != wantLines[i] {
+				t.Fatalf("output differs from %s at line %d:\ngot  %q\nwant %q\n(run 'go test ./internal/generator -update' if the change is intended)", name, i+1, gotLines[i], wantLines[i])
+			}
+		}
+		t.Fatalf("output has %d lines, %s has %d", len(gotLines), name, len(wantLines))
+	}
+}
+
+// testdataDir is resolved before any test changes directory
+var testdataDir, _ = filepath.Abs("testdata")
+
+func TestRegistry(t *testing.T) {
+	if formats := Formats(); !slices.Equal(formats, []string{"html", "markdown"}) {
+		t.Errorf("Formats() = %v, want [html markdown]", formats)
+	}
+	for _, format := range Formats() {
+		if registry[format]() == nil {
+			t.Errorf("format '%s' has no renderer", format)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering 'markdown' twice didn't panic")
+		}
+	}()
+	Register("markdown", func() Renderer { return &markdownRenderer{} })
+}
+
+func TestUnknownFormat(t *testing.T) {
+	saveFixture(t, exampleSettings())
+	settings := exampleSettings()
+	settings.DocGenFormat = models.Formats{"markdown", "pdf"}
+	g, dir := generate(t, settings)
+
+	if len(g.Diagnostics) != 1 || g.Diagnostics[0].Code != models.CodeSettings || g.Diagnostics[0].Severity != models.SeverityError {
+		t.Fatalf("diagnostics = %v, want one settings error", g.Diagnostics)
+	}
+	if !strings.Contains(g.Diagnostics[0].Message, "'pdf'") || !strings.Contains(g.Diagnostics[0].Fix, "html, markdown") {
+		t.Errorf("diagnostic = %+v, want it to name 'pdf' and list the formats", g.Diagnostics[0])
+	}
+	// The valid format isn't written either, so a typo doesn't leave half the docs generated
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("%d files written, want none", len(entries))
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string // Contents of godoc_output.json, empty to leave it out
+		modules int
+		fix     string // Fix of the error expected, if any
+	}{
+		{name: "modules", json: `[{"Path": "example.com/app", "Packages": [{"Name": "app"}]}, {"Path": "example.com/tools", "Packages": null}]`, modules: 2},
+		{name: "no modules", json: `[]`},
+		{name: "packages from an older save", json: `[{"Name": "main", "Desc": "Entry point", "Files": []}]`, fix: "run '-task save' again"},
+		{name: "invalid", json: `{"Packages": [}`, fix: "run '-task save' again"},
+		{name: "missing", fix: "run '-task save' first"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chdirTemp(t)
+			if test.json != "" {
+				if err := os.WriteFile("godoc_output.json", []byte(test.json), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			g := New(exampleSettings())
+			g.readJSON()
+
+			if test.fix == "" {
+				if len(g.Diagnostics) != 0 || len(g.Modules) != test.modules {
+					t.Errorf("modules = %+v, diagnostics = %v, want %d modules", g.Modules, g.Diagnostics, test.modules)
+				}
+				return
+			}
+			if len(g.Diagnostics) != 1 || g.Diagnostics[0].Severity != models.SeverityError || g.Diagnostics[0].Fix != test.fix {
+				t.Errorf("diagnostics = %v, want one error with the fix %q", g.Diagnostics, test.fix)
+			}
+			if len(g.Modules) != 0 {
+				t.Errorf("modules = %+v, want none", g.Modules)
+			}
+		})
+	}
+}
+
+func TestMarkdownOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings func(*models.Settings)
+		file     string // File written under DocGenPath
+		golden   string
+		example  string // Copy of the output checked in at the top of the repo, if there is one
+	}{
+		{
+			name:     "settings.json",
+			settings: func(*models.Settings) {},
+			file:     "Example.md",
+			golden:   "Example.md",
+			example:  filepath.Join("docs", "Example.md"),
+		},
+		{
+			name:     "no table of contents",
+			settings: func(s *models.Settings) { s.TOCDepth = tocDepthOf(0) },
+			file:     "Example.md",
+			golden:   "Example-notoc.md",
+		},
+		{
+			name:     "TOCDepth left out",
+			settings: func(s *models.Settings) { s.TOCDepth = nil },
+			file:     "Example.md",
+			golden:   "Example.md",
+		},
+		{
+			name:     "unnamed project",
+			settings: func(s *models.Settings) { s.ProjectName, s.ProjectDesc = "", "" },
+			file:     "Docs.md",
+			golden:   "Docs.md",
+			example:  "Docs.md",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			saveFixture(t, exampleSettings())
+			settings := exampleSettings()
+			test.settings(&settings)
+			g, dir := generate(t, settings)
+			if len(g.Diagnostics) != 0 {
+				t.Fatalf("unexpected diagnostics: %v", g.Diagnostics)
+			}
+
+			output, err := os.ReadFile(filepath.Join(dir, test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, test.golden, output)
+			if test.example != "" {
+				checkGolden(t, filepath.Join("..", "..", "..", test.example), output)
+			}
+		})
+	}
+}
+
+func TestMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings func(*models.Settings)
+		modules  []models.Module
+		code     string
+	}{
+		{name: "no packages", settings: func(*models.Settings) {}, code: models.CodeNoPackages},
+		{name: "TOCDepth too deep", settings: func(s *models.Settings) { s.TOCDepth = tocDepthOf(maxTOCDepth + 1) }, modules: []models.Module{{Path: "m"}}, code: models.CodeSettings},
+		{name: "TOCDepth negative", settings: func(s *models.Settings) { s.TOCDepth = tocDepthOf(-1) }, modules: []models.Module{{Path: "m"}}, code: models.CodeSettings},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := exampleSettings()
+			settings.DocGenPath = t.TempDir()
+			test.settings(&settings)
+			diagnostics := (&markdownRenderer{}).Render(settings, test.modules)
+			if len(diagnostics) != 1 || diagnostics[0].Code != test.code || diagnostics[0].Severity != models.SeverityError {
+				t.Errorf("diagnostics = %v, want one %s error", diagnostics, test.code)
+			}
+		})
+	}
+}
+
+// Reports can be written to stdout, so generating mustn't print anything there
+func TestStdoutLeftClean(t *testing.T) {
+	saveFixture(t, exampleSettings())
+	settings := exampleSettings()
+	settings.DocGenFormat = models.Formats{"markdown", "html"}
+
+	read, write, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = write
+	g, _ := generate(t, settings)
+	os.Stdout = stdout
+	write.Close()
+
+	printed, err := io.ReadAll(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", g.Diagnostics)
+	}
+	if len(printed) != 0 {
+		t.Errorf("generating printed %q to stdout", printed)
+	}
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+func init() {
+	Register("html", func() Renderer { return &htmlRenderer{} })
+}
+
+// htmlRenderer writes a static site to DocGenPath/html: an index page and one page per package, with every link relative
+// and the CSS inlined, so it works straight from file:// with no network. search.js and search-index.js next to
+// index.html let every page search the whole site. The pages come from the templates in templates/html,
+// which TemplateDir can override along with style.css.
+type htmlRenderer struct {
+	Settings    models.Settings
+	Diagnostics []models.Diagnostic
+}
+
+// PageView is what the html 'index' and 'package' templates run with
+type PageView struct {
+	Title       string
+	ProjectName string
+	ProjectDesc string
+	CSS         template.CSS
+	Root        string // Relative path from the page back to the site root, such as '../../'
+	Modules     []ModuleView
+	Package     PackageView // Package the page documents, empty on the index
+}
+
+func (h *htmlRenderer) Render(settings models.Settings, modules []models.Module) []models.Diagnostic {
+	h.Settings = settings
+	if len(modules) == 0 {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeNoPackages, "no packages found in the stored comment tree").WithFix("add a '-- PKG' block or enable IncludeDocComments"))
+		return h.Diagnostics
+	}
+
+	templates := h.parseTemplates()
+	css := h.readAsset("style.css")
+	script := h.readAsset("search.js")
+	searchIndex, err := searchIndexScript(buildSearchIndex(modules))
+	if err != nil {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeWriteFailed, "error encoding search index: %v", err))
+	}
+	if models.HasErrors(h.Diagnostics) {
+		return h.Diagnostics
+	}
+
+	siteDir := filepath.Join(settings.DocGenPath, "html")
+	log.Printf("Writing HTML documentation to %s\n", siteDir)
+	if !h.writeFile(filepath.Join(siteDir, "search.js"), script) || !h.writeFile(filepath.Join(siteDir, "search-index.js"), searchIndex) {
+		return h.Diagnostics
+	}
+
+	document := newDocumentView(settings, modules)
+	project := document.ProjectName
+	if project == "" {
+		project = "GoDoc generator documentation"
+	}
+	index := PageView{Title: project, ProjectName: project, ProjectDesc: document.ProjectDesc, CSS: template.CSS(css), Modules: document.Modules}
+	if !h.writePage(templates, "index", filepath.Join(siteDir, "index.html"), index) {
+		return h.Diagnostics
+	}
+	for _, mod := range document.Modules {
+		for _, pkg := range mod.Packages {
+			page := index
+			page.Title = fmt.Sprintf("%s - %s", pkg.Name, project)
+			page.Root = strings.Repeat("../", strings.Count(pkg.URL, "/"))
+			page.Package = pkg
+			if !h.writePage(templates, "package", filepath.Join(siteDir, filepath.FromSlash(pkg.URL)), page) {
+				return h.Diagnostics
+			}
+		}
+	}
+	return h.Diagnostics
+}
+
+// parseTemplates loads the html templates, built-in or from TemplateDir
+func (h *htmlRenderer) parseTemplates() *template.Template {
+	templates := template.New("html").Funcs(template.FuncMap{
+		"summary": summary,
+		"trim":    strings.TrimSpace,
+	})
+	h.Diagnostics = append(h.Diagnostics, loadTemplates(h.Settings, "html", func(kind, text string) error {
+		_, err := templates.New(kind).Parse(text)
+		return err
+	})...)
+	return templates
+}
+
+// readAsset reads one of the site's static files, from TemplateDir/html if it is there and the built-in one otherwise
+func (h *htmlRenderer) readAsset(name string) []byte {
+	path := filepath.Join("templates", "html", name)
+	data, err := defaultTemplates.ReadFile(filepath.ToSlash(path))
+	if h.Settings.TemplateDir != "" {
+		override := filepath.Join(h.Settings.TemplateDir, "html", name)
+		if custom, customErr := os.ReadFile(override); customErr == nil {
+			data, err = custom, nil
+		} else if !errors.Is(customErr, os.ErrNotExist) {
+			data, err, path = nil, customErr, override
+		}
+	}
+	if err != nil {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeIO, "error reading '%s': %v", path, err))
+	}
+	return data
+}
+
+// writeFile writes one of the site's static files, creating its directory, and returns false if that failed
+func (h *htmlRenderer) writeFile(path string, data []byte) bool {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeIO, "failed to create documentation directory '%s': %v", filepath.Dir(path), err).WithFix("check DocGenPath in settings.json"))
+		return false
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeWriteFailed, "error writing '%s': %v", path, err))
+		return false
+	}
+	return true
+}
+
+// writePage executes a page template into a file, creating its directory, and returns false if that failed
+func (h *htmlRenderer) writePage(templates *template.Template, name, path string, page PageView) bool {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeIO, "failed to create documentation directory '%s': %v", filepath.Dir(path), err).WithFix("check DocGenPath in settings.json"))
+		return false
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeIO, "failed to create '%s': %v", path, err).WithFix("check DocGenPath in settings.json"))
+		return false
+	}
+	defer file.Close()
+	if err := templates.ExecuteTemplate(file, name, page); err != nil {
+		h.Diagnostics = append(h.Diagnostics, models.Errorf(models.CodeWriteFailed, "error writing html page '%s': %v", path, err))
+		return false
+	}
+	return true
+}
+
+// summary is the first sentence of a description
+func summary(desc string) string {
+	desc = strings.TrimSpace(desc)
+	if i := strings.Index(desc, ". "); i >= 0 {
+		return desc[:i+1]
+	}
+	return desc
+}
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+var (
+	linkPattern = regexp.MustCompile(`(?:href|src)="([^"]*)"`)
+	idPattern   = regexp.MustCompile(`id="([^"]*)"`)
+)
+
+// renderSite renders the html site for modules into a temporary DocGenPath and returns the site's directory
+func renderSite(t *testing.T, settings models.Settings, modules []models.Module) string {
+	t.Helper()
+	settings.DocGenPath = t.TempDir()
+	if diagnostics := (&htmlRenderer{}).Render(settings, modules); len(diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+	return filepath.Join(settings.DocGenPath, "html")
+}
+
+func TestHTMLFiles(t *testing.T) {
+	modules := saveFixture(t, exampleSettings())
+	site := renderSite(t, exampleSettings(), modules)
+
+	want := []string{"index.html", "search.js", "search-index.js"}
+	for _, mod := range modules {
+		for _, pkg := range mod.Packages {
+			want = append(want, filepath.FromSlash(pkgURL(pkg.ImportPath)))
+		}
+	}
+	var got []string
+	filepath.WalkDir(site, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			rel, _ := filepath.Rel(site, path)
+			got = append(got, rel)
+		}
+		return err
+	})
+	if len(got) != len(want) {
+		t.Errorf("wrote %v, want %v", got, want)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(filepath.Join(site, path)); err != nil {
+			t.Errorf("'%s' wasn't written", path)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(site, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"<title>Example</title>", "This is an example documenation for GoDoc.", "internal/handler", "<style>"} {
+		if !strings.Contains(string(index), text) {
+			t.Errorf("index.html doesn't contain %q", text)
+		}
+	}
+}
+
+// Every relative link and script source has to reach a page that exists, and its fragment an id on that page
+func TestHTMLLinks(t *testing.T) {
+	site := renderSite(t, exampleSettings(), saveFixture(t, exampleSettings()))
+
+	ids := make(map[string]map[string]bool)
+	pages := make(map[string]string)
+	filepath.WalkDir(site, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || filepath.Ext(path) != ".html" {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		pages[path] = string(data)
+		ids[path] = make(map[string]bool)
+		for _, match := range idPattern.FindAllStringSubmatch(string(data), -1) {
+			ids[path][match[1]] = true
+		}
+		return err
+	})
+
+	links := 0
+	for page, text := range pages {
+		for _, match := range linkPattern.FindAllStringSubmatch(text, -1) {
+			link := match[1]
+			if strings.Contains(link, "://") {
+				t.Errorf("%s links outside the site: %s", page, link)
+				continue
+			}
+			links++
+			file, anchor, _ := strings.Cut(link, "#")
+			target := page
+			if file != "" {
+				target = filepath.Join(filepath.Dir(page), filepath.FromSlash(file))
+			}
+			if _, err := os.Stat(target); err != nil {
+				t.Errorf("%s links to missing file %s", page, link)
+				continue
+			}
+			if anchor != "" && !ids[target][anchor] {
+				t.Errorf("%s links to missing anchor %s", page, link)
+			}
+		}
+	}
+	if links == 0 {
+		t.Error("no links found")
+	}
+}
+
+func TestHTMLEscaping(t *testing.T) {
+	const script = "<script>alert('doc')</script>"
+	modules := saveFixture(t, exampleSettings())
+	modules[0].Packages[0].Desc = "Runs " + script + " & more"
+	settings := exampleSettings()
+	settings.ProjectName = "A <b>bold</b> project"
+	site := renderSite(t, settings, modules)
+
+	pages := []string{"index.html", filepath.FromSlash(pkgURL(modules[0].Packages[0].ImportPath))}
+	for _, page := range pages {
+		data, err := os.ReadFile(filepath.Join(site, page))
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := string(data)
+		if strings.Contains(text, script) || strings.Contains(text, "<b>bold</b>") {
+			t.Errorf("%s has doc text written as markup", page)
+		}
+		if !strings.Contains(text, "A &lt;b&gt;bold&lt;/b&gt; project") {
+			t.Errorf("%s doesn't show the escaped project name", page)
+		}
+	}
+	pkgPage, _ := os.ReadFile(filepath.Join(site, pages[1]))
+	if !strings.Contains(string(pkgPage), "Runs &lt;script&gt;alert(&#39;doc&#39;)&lt;/script&gt; &amp; more") {
+		t.Error("package page doesn't show the escaped description")
+	}
+}
@@ -0,0 +1,171 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+func init() {
+	Register("markdown", func() Renderer { return &markdownRenderer{} })
+}
+
+const (
+	// defaultTOCDepth is how many levels the table of contents lists when settings.json doesn't say: packages, then their files and items
+	defaultTOCDepth = 2
+	// maxTOCDepth is the deepest level the table of contents goes to: packages, then their files and items, then methods
+	maxTOCDepth = 3
+)
+
+// markdownRenderer writes every module to a single markdown file, named after the project.
+// The layout comes from the templates in templates/markdown, which TemplateDir can override.
+type markdownRenderer struct {
+	Settings    models.Settings
+	Diagnostics []models.Diagnostic
+}
+
+func (m *markdownRenderer) Render(settings models.Settings, modules []models.Module) []models.Diagnostic {
+	m.Settings = settings
+	if depth := tocDepth(settings); depth < 0 || depth > maxTOCDepth {
+		m.Diagnostics = append(m.Diagnostics, models.Errorf(models.CodeSettings, "TOCDepth %d is out of range", depth).WithFix(fmt.Sprintf("use 0 to leave the table of contents out, or 1 to %d", maxTOCDepth)))
+	}
+	templates := m.parseTemplates()
+	if models.HasErrors(m.Diagnostics) {
+		return m.Diagnostics
+	}
+
+	// Create or open the markdown file
+	docPath := "./Docs.md"
+	if m.Settings.ProjectName != "" {
+		docPath = fmt.Sprintf("%s/%s.md", m.Settings.DocGenPath, m.Settings.ProjectName)
+	} else {
+		if m.Settings.DocGenPath != "./" {
+			docPath = fmt.Sprintf("%s/Docs.md", m.Settings.DocGenPath)
+		}
+	}
+	log.Printf("Writing markdown documentation to %s\n", docPath)
+	file, err := os.Create(docPath)
+	if err != nil {
+		m.Diagnostics = append(m.Diagnostics, models.Errorf(models.CodeIO, "failed to open/create documentation path from settings '%s', ensure it exists", m.Settings.DocGenPath).WithFix("create the DocGenPath directory or change it in settings.json"))
+		return m.Diagnostics
+	}
+	defer file.Close()
+
+	if len(modules) == 0 {
+		m.Diagnostics = append(m.Diagnostics, models.Errorf(models.CodeNoPackages, "no packages found in the stored comment tree").WithFix("add a '-- PKG' block or enable IncludeDocComments"))
+	}
+	for _, mod := range modules {
+		for _, pkg := range mod.Packages {
+			if len(pkg.Files) == 0 {
+				m.Diagnostics = append(m.Diagnostics, models.Warningf(models.CodeEmptyOutput, "no files in package '%s'", pkg.ImportPath).At(pkg.Pos))
+			}
+		}
+	}
+
+	writer := bufio.NewWriter(file)
+	if err := templates.ExecuteTemplate(writer, "document", newDocumentView(m.Settings, modules)); err != nil {
+		m.Diagnostics = append(m.Diagnostics, models.Errorf(models.CodeWriteFailed, "error writing markdown: %v", err))
+		return m.Diagnostics
+	}
+
+	// Ensure all buffered content is flushed to the file
+	err = writer.Flush()
+	if err != nil {
+		m.Diagnostics = append(m.Diagnostics, models.Errorf(models.CodeWriteFailed, "error flushing writer: %v", err))
+	}
+	return m.Diagnostics
+}
+
+// parseTemplates loads the markdown templates, built-in or from TemplateDir
+func (m *markdownRenderer) parseTemplates() *template.Template {
+	var templates *template.Template
+	funcs := template.FuncMap{
+		// render runs another template and returns its output, so it can be indented with pad
+		"render": func(kind string, data any) (string, error) {
+			var out strings.Builder
+			err := templates.ExecuteTemplate(&out, kind, data)
+			return out.String(), err
+		},
+		"pad":    padText,
+		"indent": func(width int, text string) string { return indentText(text, width) },
+		"cell":   tableCell,
+		"join":   func(items []string, sep string) string { return strings.Join(items, sep) },
+	}
+	templates = template.New("markdown").Funcs(funcs)
+	m.Diagnostics = append(m.Diagnostics, loadTemplates(m.Settings, "markdown", func(kind, text string) error {
+		_, err := templates.New(kind).Parse(text)
+		return err
+	})...)
+	return templates
+}
+
+// tocDepth is the TOCDepth setting, or the default when settings.json leaves it out. 0 is kept, since it turns the table off.
+func tocDepth(settings models.Settings) int {
+	if settings.TOCDepth == nil {
+		return defaultTOCDepth
+	}
+	return *settings.TOCDepth
+}
+
+// padText indents every non-empty line of text by width spaces, to nest one template's output inside another's list
+func padText(width int, text string) string {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		if lines[i] != "" {
+			lines[i] = strings.Repeat(" ", width) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableCell fits text into one cell of a markdown table
+func tableCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	text = strings.ReplaceAll(text, "\n\n", "<br>")
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// constsOfType returns the const groups that belong to a type
+func constsOfType(groups []models.ConstGroup, typeName string) []models.ConstGroup {
+	var owned []models.ConstGroup
+	for _, group := range groups {
+		if group.Type == typeName {
+			owned = append(owned, group)
+		}
+	}
+	return owned
+}
+
+// constsWithoutType returns the const groups that don't belong to any of the documented types
+func constsWithoutType(groups []models.ConstGroup, types []models.Type) []models.ConstGroup {
+	var rest []models.ConstGroup
+	for _, group := range groups {
+		owned := false
+		for _, _type := range types {
+			if group.Type != "" && group.Type == _type.Name {
+				owned = true
+			}
+		}
+		if !owned {
+			rest = append(rest, group)
+		}
+	}
+	return rest
+}
+
+// indentText indents every line of a multi-line description after the first, so paragraphs, lists and code
+// stay inside the list item the description is written under
+func indentText(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = strings.Repeat(" ", width) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
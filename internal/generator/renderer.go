@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"sort"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// Renderer writes the saved documentation in one output format, under the settings' DocGenPath.
+// Each format registers itself with Register, so adding one never touches the generator.
+type Renderer interface {
+	Render(settings models.Settings, modules []models.Module) []models.Diagnostic
+}
+
+// registry maps a DocGenFormat name to a constructor for its renderer
+var registry = make(map[string]func() Renderer)
+
+// Register makes a renderer available under a DocGenFormat name. Registering the same name twice panics.
+func Register(format string, newRenderer func() Renderer) {
+	if _, exists := registry[format]; exists {
+		panic("generator: format '" + format + "' registered twice")
+	}
+	registry[format] = newRenderer
+}
+
+// Formats lists the registered format names in order
+func Formats() []string {
+	var formats []string
+	for format := range registry {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
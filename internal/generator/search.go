@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// Kinds of search index entries, in the order search.js lists them for equally good matches
+const (
+	searchPackage = iota
+	searchType
+	searchInterface
+	searchFunc
+	searchMethod
+	searchField
+	searchVar
+	searchConst
+	searchParam
+)
+
+// searchIndex is the index search.js queries. Entries are arrays rather than objects to keep the file small:
+// [name, kind, owner, package, description, anchor], where owner is the type or func the entry belongs to, if any,
+// and package indexes Packages.
+type searchIndex struct {
+	Packages [][2]string `json:"packages"` // [import path, page URL relative to the site root]
+	Entries  [][]any     `json:"entries"`
+}
+
+// buildSearchIndex lists every package and everything documented in it, in the order the pages show them
+func buildSearchIndex(modules []models.Module) searchIndex {
+	var index searchIndex
+	for _, mod := range modules {
+		for _, pkg := range mod.Packages {
+			pkgIndex := len(index.Packages)
+			index.Packages = append(index.Packages, [2]string{pkg.ImportPath, pkgURL(pkg.ImportPath)})
+			add := func(name string, kind int, owner, desc, anchor string) {
+				index.Entries = append(index.Entries, []any{name, kind, owner, pkgIndex, strings.Join(strings.Fields(desc), " "), anchor})
+			}
+
+			add(pkg.Name, searchPackage, "", pkg.Desc, "")
+			files := append([]models.File{{Funcs: pkg.Funcs, Vars: pkg.Vars, Consts: pkg.Consts, Types: pkg.Types, Interfaces: pkg.Interfaces}}, pkg.Files...)
+			for _, file := range files {
+				for _, group := range file.Consts {
+					for _, value := range group.Values {
+						add(value.Name, searchConst, "", value.Desc+" "+group.Desc, value.Name)
+					}
+				}
+				for _, variable := range file.Vars {
+					add(variable.Name, searchVar, "", variable.Desc, variable.Name)
+				}
+				for _, function := range file.Funcs {
+					addFunc(add, function, searchFunc, "")
+				}
+				for _, _type := range file.Types {
+					add(_type.Name, searchType, "", _type.Desc, _type.Name)
+					for _, field := range _type.Fields {
+						add(field.Name, searchField, _type.Name, field.Desc, _type.Name+"."+field.Name)
+					}
+					for _, function := range _type.Constructors {
+						addFunc(add, function, searchFunc, "")
+					}
+					for _, function := range _type.Methods {
+						addFunc(add, function, searchMethod, _type.Name)
+					}
+				}
+				for _, iface := range file.Interfaces {
+					add(iface.Name, searchInterface, "", iface.Desc, iface.Name)
+					for _, function := range iface.Methods {
+						addFunc(add, function, searchMethod, iface.Name)
+					}
+				}
+			}
+		}
+	}
+	return index
+}
+
+// addFunc adds a func or method and its parameters, which all point at the func's anchor
+func addFunc(add func(name string, kind int, owner, desc, anchor string), function models.Func, kind int, owner string) {
+	anchor := function.Name
+	if owner != "" {
+		anchor = owner + "." + function.Name
+	}
+	add(function.Name, kind, owner, function.Desc, anchor)
+	for _, param := range function.Params {
+		add(param.Name, searchParam, anchor, param.Desc, anchor)
+	}
+}
+
+// searchIndexScript is the index as a script that sets a global, so pages opened from file:// can load it with a <script> tag
+func searchIndexScript(index searchIndex) ([]byte, error) {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	script := "window.GODOC_SEARCH_INDEX = " + string(data) + ";\n"
+	return []byte(script), nil
+}
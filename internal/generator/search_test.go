@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// searchModules is a small package with one of everything the index lists, and names chosen to exercise the ranking
+func searchModules() []models.Module {
+	return []models.Module{{
+		Path: "example.com/app",
+		Packages: []models.Package{{
+			Name:       "users",
+			ImportPath: "example.com/app/users",
+			Desc:       "Stores user\naccounts.",
+			Files: []models.File{{
+				Name:   "users.go",
+				Consts: []models.ConstGroup{{Desc: "Limits.", Values: []models.Const{{Name: "MaxUsers", Desc: "Most accounts."}}}},
+				Vars:   []models.Var{{Name: "DefaultUser", Desc: "Guest account."}},
+				Funcs: []models.Func{
+					{Name: "ParseUsers", Desc: "Reads a list."},
+					{Name: "Lookup", Desc: "Finds a user by name.", Params: []models.Var{{Name: "name", Desc: "Login name."}}},
+				},
+				Types: []models.Type{
+					{
+						Name:         "User",
+						Desc:         "A user account.",
+						Fields:       []models.Var{{Name: "ID", Desc: "Primary key."}},
+						Constructors: []models.Func{{Name: "NewUser", Desc: "Makes one."}},
+					},
+					{
+						Name:    "UserService",
+						Desc:    "Serves accounts.",
+						Methods: []models.Func{{Name: "GetUser", Desc: "Fetches one.", Params: []models.Var{{Name: "id", Desc: "Primary key."}}}},
+					},
+				},
+				Interfaces: []models.Interface{{Name: "Store", Desc: "Saves accounts.", Methods: []models.Func{{Name: "Save", Desc: "Writes one."}}}},
+			}},
+		}},
+	}}
+}
+
+func TestBuildSearchIndex(t *testing.T) {
+	index := buildSearchIndex(searchModules())
+
+	if want := [][2]string{{"example.com/app/users", "pkg/example.com/app/users/index.html"}}; !reflect.DeepEqual(index.Packages, want) {
+		t.Errorf("packages = %v, want %v", index.Packages, want)
+	}
+	want := [][]any{
+		{"users", searchPackage, "", 0, "Stores user accounts.", ""},
+		{"MaxUsers", searchConst, "", 0, "Most accounts. Limits.", "MaxUsers"},
+		{"DefaultUser", searchVar, "", 0, "Guest account.", "DefaultUser"},
+		{"ParseUsers", searchFunc, "", 0, "Reads a list.", "ParseUsers"},
+		{"Lookup", searchFunc, "", 0, "Finds a user by name.", "Lookup"},
+		{"name", searchParam, "Lookup", 0, "Login name.", "Lookup"},
+		{"User", searchType, "", 0, "A user account.", "User"},
+		{"ID", searchField, "User", 0, "Primary key.", "User.ID"},
+		{"NewUser", searchFunc, "", 0, "Makes one.", "NewUser"},
+		{"UserService", searchType, "", 0, "Serves accounts.", "UserService"},
+		{"GetUser", searchMethod, "UserService", 0, "Fetches one.", "UserService.GetUser"},
+		{"id", searchParam, "UserService.GetUser", 0, "Primary key.", "UserService.GetUser"},
+		{"Store", searchInterface, "", 0, "Saves accounts.", "Store"},
+		{"Save", searchMethod, "Store", 0, "Writes one.", "Store.Save"},
+	}
+	if len(index.Entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %v", len(index.Entries), len(want), index.Entries)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(index.Entries[i], want[i]) {
+			t.Errorf("entry %d = %v, want %v", i, index.Entries[i], want[i])
+		}
+	}
+
+	script, err := searchIndexScript(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, found := strings.CutPrefix(string(script), "window.GODOC_SEARCH_INDEX = ")
+	var decoded searchIndex
+	if !found || json.Unmarshal([]byte(strings.TrimSuffix(data, ";\n")), &decoded) != nil || len(decoded.Entries) != len(want) {
+		t.Errorf("script doesn't set the index global: %.80s", script)
+	}
+}
+
+// Every entry of the fixture's index has to point at an id on its package's page
+func TestSearchIndexAnchors(t *testing.T) {
+	modules := saveFixture(t, exampleSettings())
+	site := renderSite(t, exampleSettings(), modules)
+
+	index := buildSearchIndex(modules)
+	for _, entry := range index.Entries {
+		anchor := entry[5].(string)
+		if anchor == "" {
+			continue
+		}
+		page := index.Packages[entry[3].(int)][1]
+		data, err := os.ReadFile(filepath.Join(site, filepath.FromSlash(page)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `id="`+anchor+`"`) {
+			t.Errorf("%s has no anchor for %s", page, anchor)
+		}
+	}
+}
+
+// searchHarness loads search.js with a stub document, since it only wires itself up on DOMContentLoaded,
+// and prints the names search() ranks for each query on its command line
+const searchHarness = `
+const fs = require("fs");
+global.window = {};
+global.document = { addEventListener: function () {} };
+eval(fs.readFileSync(process.argv[2], "utf8"));
+const source = fs.readFileSync(process.argv[3], "utf8").replace("function render(", "window.search = search;\n  function render(");
+eval(source);
+const results = process.argv.slice(4).map(function (query) {
+  return window.search(window.GODOC_SEARCH_INDEX, query).map(function (hit) {
+    return (hit.entry[2] ? hit.entry[2] + "." : "") + hit.entry[0];
+  });
+});
+console.log(JSON.stringify(results));
+`
+
+func TestSearchRanking(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node isn't installed")
+	}
+
+	dir := t.TempDir()
+	script, err := searchIndexScript(buildSearchIndex(searchModules()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := defaultTemplates.ReadFile("templates/html/search.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{"harness.js": []byte(searchHarness), "search-index.js": script, "search.js": source}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Exact, then prefix by kind, then substring of the name or owner by kind and length, then description
+		{query: "User", want: []string{"User", "users", "UserService", "NewUser", "ParseUsers", "UserService.GetUser", "User.ID", "DefaultUser", "MaxUsers", "UserService.GetUser.id", "Lookup"}},
+		{query: "UserService.GetUser", want: []string{"UserService.GetUser", "UserService.GetUser.id"}},
+		{query: "userservice.getuser", want: []string{"UserService.GetUser", "UserService.GetUser.id"}},
+		{query: "id", want: []string{"UserService.GetUser.id", "User.ID"}},
+		{query: "primary key", want: []string{"User.ID", "UserService.GetUser.id"}},
+		{query: "  user   account ", want: []string{"users", "User"}},
+		{query: "save", want: []string{"Store.Save", "Store"}},
+		{query: "nothing", want: []string{}},
+		{query: "   ", want: []string{}},
+	}
+	args := []string{filepath.Join(dir, "harness.js"), filepath.Join(dir, "search-index.js"), filepath.Join(dir, "search.js")}
+	for _, test := range tests {
+		args = append(args, test.query)
+	}
+	output, err := exec.Command(node, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("node failed: %v\n%s", err, output)
+	}
+	var results [][]string
+	if err := json.Unmarshal(output, &results); err != nil {
+		t.Fatalf("harness output isn't JSON: %v\n%s", err, output)
+	}
+
+	for i, test := range tests {
+		if !reflect.DeepEqual(results[i], test.want) {
+			t.Errorf("search(%q) = %v, want %v", test.query, results[i], test.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// defaultTemplates holds the built-in templates of every format, at templates/<format>/<kind>.tmpl
+//
+//go:embed templates
+var defaultTemplates embed.FS
+
+// templateKinds lists the templates each format is made of, one per kind of entity it shows.
+// Each is named after its kind, so templates call each other by kind.
+var templateKinds = map[string][]string{
+	"markdown": {"document", "toc", "module", "package", "file", "type", "interface", "consts", "func", "var"},
+	"html":     {"layout", "index", "package", "file", "type", "interface", "consts", "func", "var"},
+}
+
+// loadTemplates reads every template of a format and passes it to parse along with its kind.
+// A template in TemplateDir/<format>/<kind>.tmpl replaces the built-in one, so a theme only needs the ones it changes.
+func loadTemplates(settings models.Settings, format string, parse func(kind, text string) error) []models.Diagnostic {
+	var diagnostics []models.Diagnostic
+	kinds := templateKinds[format]
+	for _, kind := range kinds {
+		name := kind + ".tmpl"
+		path := filepath.Join("templates", format, name)
+		text, err := defaultTemplates.ReadFile(filepath.ToSlash(path))
+		if settings.TemplateDir != "" {
+			override := filepath.Join(settings.TemplateDir, format, name)
+			if custom, customErr := os.ReadFile(override); customErr == nil {
+				text, err, path = custom, nil, override
+			} else if !errors.Is(customErr, os.ErrNotExist) {
+				text, err, path = nil, customErr, override
+			}
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, models.Errorf(models.CodeIO, "error reading template '%s': %v", path, err))
+			continue
+		}
+		if err := parse(kind, string(text)); err != nil {
+			diagnostics = append(diagnostics, models.Errorf(models.CodeSettings, "template '%s' is invalid: %v", path, err).WithFix("see the built-in templates in internal/generator/templates for the view model they run with").At(models.Position{File: path}))
+		}
+	}
+
+	// Any other template in the format's directory would be silently ignored, which is never what was meant
+	if settings.TemplateDir != "" {
+		entries, _ := os.ReadDir(filepath.Join(settings.TemplateDir, format))
+		for _, entry := range entries {
+			kind, isTemplate := strings.CutSuffix(entry.Name(), ".tmpl")
+			if isTemplate && !slices.Contains(kinds, kind) {
+				path := filepath.Join(settings.TemplateDir, format, entry.Name())
+				diagnostics = append(diagnostics, models.Warningf(models.CodeSettings, "template '%s' doesn't replace any %s template", path, format).WithFix(fmt.Sprintf("name it after one of %s, with a .tmpl extension", strings.Join(kinds, ", "))).At(models.Position{File: path}))
+			}
+		}
+	}
+	return diagnostics
+}
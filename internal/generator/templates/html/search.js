@@ -0,0 +1,100 @@
+// Offline search for the GoDoc static site. The index is loaded from search-index.js by a plain
+// <script> tag rather than fetched, since browsers block fetch() on file:// pages.
+(function () {
+  "use strict";
+
+  var KINDS = ["package", "type", "interface", "func", "method", "field", "var", "const", "param"];
+  var MAX_RESULTS = 20;
+
+  // rank scores how well an entry matches, lower is better. Exact identifier matches come first,
+  // then prefix and substring matches on the name, then matches in the description.
+  function rank(entry, query, words) {
+    var name = entry[0];
+    var qualified = entry[2] ? entry[2] + "." + name : name;
+    if (name === query.raw || qualified === query.raw) {
+      return 0;
+    }
+    var lowerName = name.toLowerCase();
+    if (lowerName === query.lower || qualified.toLowerCase() === query.lower) {
+      return 1;
+    }
+    if (lowerName.indexOf(query.lower) === 0) {
+      return 2;
+    }
+    if (lowerName.indexOf(query.lower) >= 0 || qualified.toLowerCase().indexOf(query.lower) >= 0) {
+      return 3;
+    }
+    var desc = entry[4].toLowerCase();
+    for (var i = 0; i < words.length; i++) {
+      if (desc.indexOf(words[i]) < 0) {
+        return -1;
+      }
+    }
+    return 4;
+  }
+
+  function search(index, raw) {
+    var query = { raw: raw.trim(), lower: raw.trim().toLowerCase() };
+    if (query.lower === "") {
+      return [];
+    }
+    var words = query.lower.split(/\s+/);
+    var hits = [];
+    for (var i = 0; i < index.entries.length; i++) {
+      var score = rank(index.entries[i], query, words);
+      if (score >= 0) {
+        hits.push({ score: score, entry: index.entries[i] });
+      }
+    }
+    hits.sort(function (a, b) {
+      return a.score - b.score ||
+        a.entry[1] - b.entry[1] ||
+        a.entry[0].length - b.entry[0].length ||
+        (a.entry[0] < b.entry[0] ? -1 : a.entry[0] > b.entry[0] ? 1 : 0);
+    });
+    return hits.slice(0, MAX_RESULTS);
+  }
+
+  function render(list, hits, index, root) {
+    list.innerHTML = "";
+    for (var i = 0; i < hits.length; i++) {
+      var entry = hits[i].entry;
+      var pkg = index.packages[entry[3]];
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.href = root + pkg[1] + (entry[5] ? "#" + entry[5] : "");
+      var title = document.createElement("code");
+      title.textContent = entry[2] ? entry[2] + "." + entry[0] : entry[0];
+      var meta = document.createElement("span");
+      meta.className = "muted";
+      meta.textContent = " " + KINDS[entry[1]] + (entry[1] === 0 ? "" : " in " + pkg[0]);
+      link.appendChild(title);
+      link.appendChild(meta);
+      item.appendChild(link);
+      list.appendChild(item);
+    }
+    list.hidden = hits.length === 0;
+  }
+
+  document.addEventListener("DOMContentLoaded", function () {
+    var input = document.getElementById("search");
+    var list = document.getElementById("search-results");
+    var index = window.GODOC_SEARCH_INDEX;
+    if (!input || !list || !index) {
+      return;
+    }
+    var root = input.getAttribute("data-root") || "";
+    input.addEventListener("input", function () {
+      render(list, search(index, input.value), index, root);
+    });
+    input.addEventListener("keydown", function (event) {
+      var first = list.querySelector("a");
+      if (event.key === "Enter" && first) {
+        window.location.href = first.href;
+      } else if (event.key === "Escape") {
+        input.value = "";
+        render(list, [], index, root);
+      }
+    });
+  });
+})();
@@ -0,0 +1,183 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// writeTemplates creates a TemplateDir holding the given files, keyed by their path inside it
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadTemplates(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string // Files in TemplateDir, none to use no TemplateDir
+		diagnostic string            // Code of the one diagnostic expected, if any
+		severity   models.Severity
+	}{
+		{name: "built-in"},
+		{
+			name:  "override",
+			files: map[string]string{"markdown/func.tmpl": "custom func", "html/func.tmpl": "not markdown"},
+		},
+		{
+			name:  "missing format directory",
+			files: map[string]string{"html/func.tmpl": "not markdown"},
+		},
+		{
+			name:       "unknown template",
+			files:      map[string]string{"markdown/method.tmpl": "custom method"},
+			diagnostic: models.CodeSettings,
+			severity:   models.SeverityWarning,
+		},
+		{
+			name:       "unreadable override",
+			files:      map[string]string{"markdown/var.tmpl/README": "a directory, not a template"},
+			diagnostic: models.CodeIO,
+			severity:   models.SeverityError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var settings models.Settings
+			if test.files != nil {
+				settings.TemplateDir = writeTemplates(t, test.files)
+			}
+
+			parsed := make(map[string]string)
+			diagnostics := loadTemplates(settings, "markdown", func(kind, text string) error {
+				parsed[kind] = text
+				return nil
+			})
+
+			if test.diagnostic == "" {
+				if len(diagnostics) != 0 {
+					t.Fatalf("unexpected diagnostics: %v", diagnostics)
+				}
+			} else if len(diagnostics) != 1 || diagnostics[0].Code != test.diagnostic || diagnostics[0].Severity != test.severity {
+				t.Fatalf("diagnostics = %v, want one %s %s", diagnostics, test.severity, test.diagnostic)
+			}
+
+			// Every kind falls back to the built-in template unless it was overridden
+			for _, kind := range templateKinds["markdown"] {
+				builtIn, err := defaultTemplates.ReadFile("templates/markdown/" + kind + ".tmpl")
+				if err != nil {
+					t.Fatal(err)
+				}
+				text, ok := parsed[kind]
+				overridden := test.files["markdown/"+kind+".tmpl"]
+				switch {
+				case overridden != "" && text != overridden:
+					t.Errorf("%s = %q, want the override", kind, text)
+				case overridden == "" && ok && text != string(builtIn):
+					t.Errorf("%s isn't the built-in template", kind)
+				case overridden == "" && !ok && test.diagnostic != models.CodeIO:
+					t.Errorf("%s wasn't parsed", kind)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateOverrideOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		files  map[string]string
+		file   string   // File the override shows up in, under DocGenPath
+		want   []string // Text from the overrides, and from built-in templates that weren't overridden
+	}{
+		{
+			name:   "markdown func",
+			format: "markdown",
+			files:  map[string]string{"markdown/func.tmpl": "CUSTOM {{.Name}}\n"},
+			file:   "Example.md",
+			want:   []string{"CUSTOM GetAllUsers", "# Example"},
+		},
+		{
+			name:   "html page and stylesheet",
+			format: "html",
+			files:  map[string]string{"html/func.tmpl": "<p>CUSTOM {{.Name}}</p>", "html/style.css": "body { color: teal; }"},
+			file:   filepath.Join("html", "pkg", "github.com", "ajtroup1", "GoDocsExample", "internal", "handler", "index.html"),
+			want:   []string{"<p>CUSTOM GetAllUsers</p>", "body { color: teal; }", `src="../../../../../../search.js"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			saveFixture(t, exampleSettings())
+			settings := exampleSettings()
+			settings.DocGenFormat = models.Formats{test.format}
+			settings.TemplateDir = writeTemplates(t, test.files)
+			g, dir := generate(t, settings)
+			if len(g.Diagnostics) != 0 {
+				t.Fatalf("unexpected diagnostics: %v", g.Diagnostics)
+			}
+
+			output, err := os.ReadFile(filepath.Join(dir, test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, text := range test.want {
+				if !strings.Contains(string(output), text) {
+					t.Errorf("%s doesn't contain %q", test.file, text)
+				}
+			}
+		})
+	}
+}
+
+// A broken override is reported against its file and nothing is written
+func TestBrokenTemplateOverride(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		code string
+	}{
+		{name: "syntax", text: "{{.Name", code: models.CodeSettings},
+		{name: "unknown function", text: "{{shout .Name}}", code: models.CodeSettings},
+		{name: "missing field", text: "{{.Nope}}", code: models.CodeWriteFailed},
+	}
+
+	for _, format := range []string{"markdown", "html"} {
+		for _, test := range tests {
+			t.Run(format+" "+test.name, func(t *testing.T) {
+				saveFixture(t, exampleSettings())
+				settings := exampleSettings()
+				settings.DocGenFormat = models.Formats{format}
+				settings.TemplateDir = writeTemplates(t, map[string]string{format + "/func.tmpl": test.text})
+				g, dir := generate(t, settings)
+
+				if !models.HasErrors(g.Diagnostics) || g.Diagnostics[0].Code != test.code {
+					t.Fatalf("diagnostics = %v, want a %s error", g.Diagnostics, test.code)
+				}
+				if test.code == models.CodeSettings {
+					if want := filepath.Join(settings.TemplateDir, format, "func.tmpl"); g.Diagnostics[0].Pos.File != want {
+						t.Errorf("diagnostic is at '%s', want '%s'", g.Diagnostics[0].Pos.File, want)
+					}
+					if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+						t.Errorf("%d files written, want none", len(entries))
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// The view model is what every template runs with. It is built from the saved models.Module tree,
+// with each item already placed where the docs show it: constants under the type they belong to,
+// constructors and methods under their type, and unexported items under the file they are declared in.
+// Descriptions are kept exactly as written in the GoDoc blocks.
+//
+// Anchor names an item within its package's page, the way pkg.go.dev does ('User', 'UserService.GetAllUsers').
+// Slug is the anchor qualified by its package ('service.UserService.GetAllUsers'), so it is unique across the
+// whole markdown file. Packages are qualified by their name, or by their import path when another package shares it.
+
+// DocumentView is what the markdown 'document' template runs with
+type DocumentView struct {
+	ProjectName string
+	ProjectDesc string
+	TOCDepth    int // Levels the table of contents lists, 0 to leave it out
+	Modules     []ModuleView
+}
+
+type ModuleView struct {
+	Path      string // Module path from go.mod, empty for packages outside any module
+	GoVersion string
+	Packages  []PackageView
+}
+
+type PackageView struct {
+	Name       string
+	ImportPath string
+	RelPath    string // Import path relative to its module, or the whole import path outside one
+	URL        string // Page of the package in the html site, relative to the site root
+	Slug       string
+	Desc       string
+	Files      []FileView
+	Types      []TypeView
+	Interfaces []InterfaceView
+	Funcs      []FuncView       // Funcs that aren't constructors of one of the types
+	Consts     []ConstGroupView // Const groups that don't belong to one of the types
+	Vars       []VarView
+}
+
+// FileView is a file of a package, along with the unexported items declared in it
+type FileView struct {
+	Name       string // From the file's block, or the file name if the block doesn't give one
+	Path       string
+	Anchor     string
+	Slug       string
+	Desc       string
+	Author     string
+	Version    string
+	Date       string
+	Build      string // Build constraint, empty if the file is built on every platform
+	Types      []TypeView
+	Interfaces []InterfaceView
+	Funcs      []FuncView
+	Consts     []ConstGroupView
+	Vars       []VarView
+}
+
+type TypeView struct {
+	Name         string
+	Anchor       string
+	Slug         string
+	Desc         string
+	Fields       []VarView
+	Consts       []ConstGroupView // Const groups of the type, such as an enum's values
+	Constructors []FuncView
+	Methods      []FuncView
+}
+
+type InterfaceView struct {
+	Name         string
+	Anchor       string
+	Slug         string
+	Desc         string
+	Methods      []FuncView
+	Implementers []string // Types that implement the interface, qualified by package outside its own
+}
+
+type FuncView struct {
+	Name      string
+	Anchor    string // 'Name', or 'Owner.Name' for methods
+	Slug      string
+	Owner     string // Type or interface the func is a method of, empty otherwise
+	Receiver  string
+	Signature string // The func as it is declared, such as 'func (UserService) GetUserByID(id int) (model.User, error)'
+	Desc      string
+	Params    []VarView
+	Returns   []ResultView
+	Responses []ResultView // HTTP responses, from '@response' tags
+}
+
+// ResultView is a return value or HTTP response
+type ResultView struct {
+	Type string
+	Desc string
+}
+
+// VarView is a variable, struct field or parameter. Parameters have no anchor.
+type VarView struct {
+	Name   string
+	Anchor string
+	Slug   string
+	Type   string
+	Desc   string
+}
+
+type ConstGroupView struct {
+	Name   string
+	Type   string // Type the constants belong to, if they share one declared in the package
+	Desc   string
+	Values []ConstView
+}
+
+type ConstView struct {
+	Name   string
+	Anchor string
+	Slug   string
+	Type   string
+	Value  string // Evaluated from the declaration, empty if it couldn't be worked out
+	Desc   string
+}
+
+// HasInternals reports whether the file has any unexported items to show
+func (f FileView) HasInternals() bool {
+	return len(f.Types)+len(f.Interfaces)+len(f.Funcs)+len(f.Consts)+len(f.Vars) > 0
+}
+
+// Internals returns the files that have unexported items to show
+func (p PackageView) Internals() []FileView {
+	var files []FileView
+	for _, file := range p.Files {
+		if file.HasInternals() {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func newDocumentView(settings models.Settings, modules []models.Module) DocumentView {
+	view := DocumentView{ProjectName: settings.ProjectName, ProjectDesc: settings.ProjectDesc, TOCDepth: tocDepth(settings)}
+	slugs := pkgSlugs(modules)
+	for _, mod := range modules {
+		view.Modules = append(view.Modules, newModuleView(mod, slugs))
+	}
+	return view
+}
+
+func newModuleView(mod models.Module, slugs map[string]string) ModuleView {
+	view := ModuleView{Path: mod.Path, GoVersion: mod.GoVersion}
+	for _, pkg := range mod.Packages {
+		view.Packages = append(view.Packages, newPackageView(mod, pkg, slugs[pkg.ImportPath]))
+	}
+	return view
+}
+
+// newPackageView builds the view of a package, whose items' slugs all start with pkgSlug
+func newPackageView(mod models.Module, pkg models.Package, pkgSlug string) PackageView {
+	view := PackageView{
+		Name:       pkg.Name,
+		ImportPath: pkg.ImportPath,
+		RelPath:    shortPath(mod.Path, pkg.ImportPath),
+		URL:        pkgURL(pkg.ImportPath),
+		Slug:       pkgSlug,
+		Desc:       pkg.Desc,
+		Types:      newTypeViews(pkg.Types, pkg.Consts, pkgSlug),
+		Interfaces: newInterfaceViews(pkg.Interfaces, pkgSlug),
+		Funcs:      newFuncViews(pkg.Funcs, "", pkgSlug),
+		Consts:     newConstGroupViews(constsWithoutType(pkg.Consts, pkg.Types), pkgSlug),
+		Vars:       newVarViews(pkg.Vars, "", pkgSlug),
+	}
+	for _, file := range pkg.Files {
+		view.Files = append(view.Files, newFileView(file, pkgSlug))
+	}
+	return view
+}
+
+// pkgSlugs gives every package the slug its items' slugs start with: its name, or its import path if another
+// package shares the name. Both are made unique with a numbered suffix, in case two packages still end up alike.
+func pkgSlugs(modules []models.Module) map[string]string {
+	names := make(map[string]int)
+	for _, mod := range modules {
+		for _, pkg := range mod.Packages {
+			names[pkg.Name]++
+		}
+	}
+
+	slugs := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, mod := range modules {
+		for _, pkg := range mod.Packages {
+			slug := pkg.Name
+			if names[pkg.Name] > 1 || slug == "" {
+				slug = slugify(pkg.ImportPath)
+			}
+			unique := slug
+			for i := 2; taken[unique]; i++ {
+				unique = fmt.Sprintf("%s-%d", slug, i)
+			}
+			taken[unique] = true
+			slugs[pkg.ImportPath] = unique
+		}
+	}
+	return slugs
+}
+
+// slugify replaces everything but letters, digits, '_' and '.' with '-', such as 'github.com-ajtroup1-GoDocsExample-cmd'
+func slugify(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, text)
+}
+
+func newFileView(file models.File, pkgSlug string) FileView {
+	name := file.Name
+	if name == "" {
+		name = filepath.Base(file.Path)
+	}
+	return FileView{
+		Name:       name,
+		Path:       file.Path,
+		Anchor:     "file-" + name,
+		Slug:       pkgSlug + ".file-" + name,
+		Desc:       file.Desc,
+		Author:     file.Author,
+		Version:    file.Version,
+		Date:       file.Date,
+		Build:      file.Build,
+		Types:      newTypeViews(file.Types, file.Consts, pkgSlug),
+		Interfaces: newInterfaceViews(file.Interfaces, pkgSlug),
+		Funcs:      newFuncViews(file.Funcs, "", pkgSlug),
+		Consts:     newConstGroupViews(constsWithoutType(file.Consts, file.Types), pkgSlug),
+		Vars:       newVarViews(file.Vars, "", pkgSlug),
+	}
+}
+
+// newTypeViews builds the views of types, with the const groups among consts that belong to each
+func newTypeViews(types []models.Type, consts []models.ConstGroup, pkgSlug string) []TypeView {
+	var views []TypeView
+	for _, _type := range types {
+		views = append(views, TypeView{
+			Name:         _type.Name,
+			Anchor:       _type.Name,
+			Slug:         pkgSlug + "." + _type.Name,
+			Desc:         _type.Desc,
+			Fields:       newVarViews(_type.Fields, _type.Name, pkgSlug),
+			Consts:       newConstGroupViews(constsOfType(consts, _type.Name), pkgSlug),
+			Constructors: newFuncViews(_type.Constructors, "", pkgSlug),
+			Methods:      newFuncViews(_type.Methods, _type.Name, pkgSlug),
+		})
+	}
+	return views
+}
+
+func newInterfaceViews(interfaces []models.Interface, pkgSlug string) []InterfaceView {
+	var views []InterfaceView
+	for _, iface := range interfaces {
+		views = append(views, InterfaceView{
+			Name:         iface.Name,
+			Anchor:       iface.Name,
+			Slug:         pkgSlug + "." + iface.Name,
+			Desc:         iface.Desc,
+			Methods:      newFuncViews(iface.Methods, iface.Name, pkgSlug),
+			Implementers: iface.Implementers,
+		})
+	}
+	return views
+}
+
+// newFuncViews builds the views of funcs, which are methods of owner if it isn't empty
+func newFuncViews(funcs []models.Func, owner, pkgSlug string) []FuncView {
+	var views []FuncView
+	for _, function := range funcs {
+		view := FuncView{
+			Name:      function.Name,
+			Anchor:    function.Name,
+			Owner:     owner,
+			Receiver:  function.Receiver,
+			Signature: signature(function),
+			Desc:      function.Desc,
+			Params:    newParamViews(function.Params),
+			Returns:   newResultViews(function.Returns),
+			Responses: newResultViews(function.Responses),
+		}
+		if owner != "" {
+			view.Anchor = owner + "." + function.Name
+		}
+		view.Slug = pkgSlug + "." + view.Anchor
+		views = append(views, view)
+	}
+	return views
+}
+
+func newResultViews(results []models.ReturnResponse) []ResultView {
+	var views []ResultView
+	for _, result := range results {
+		views = append(views, ResultView{Type: result.Paren, Desc: result.Desc})
+	}
+	return views
+}
+
+// newVarViews builds the views of variables, or of the fields of owner if it isn't empty
+func newVarViews(vars []models.Var, owner, pkgSlug string) []VarView {
+	var views []VarView
+	for _, variable := range vars {
+		view := VarView{Name: variable.Name, Anchor: variable.Name, Type: variable.Type, Desc: variable.Desc}
+		if owner != "" {
+			view.Anchor = owner + "." + variable.Name
+		}
+		view.Slug = pkgSlug + "." + view.Anchor
+		views = append(views, view)
+	}
+	return views
+}
+
+// newParamViews builds the views of a func's parameters, which have no anchor of their own
+func newParamViews(params []models.Var) []VarView {
+	var views []VarView
+	for _, param := range params {
+		views = append(views, VarView{Name: param.Name, Type: param.Type, Desc: param.Desc})
+	}
+	return views
+}
+
+func newConstGroupViews(groups []models.ConstGroup, pkgSlug string) []ConstGroupView {
+	var views []ConstGroupView
+	for _, group := range groups {
+		view := ConstGroupView{Name: group.Name, Type: group.Type, Desc: group.Desc}
+		for _, value := range group.Values {
+			view.Values = append(view.Values, ConstView{Name: value.Name, Anchor: value.Name, Slug: pkgSlug + "." + value.Name, Type: value.Type, Value: value.Value, Desc: value.Desc})
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// shortPath is a package's import path without its module path, or the module path itself for the module's root package
+func shortPath(modPath, importPath string) string {
+	if modPath == "" || importPath == modPath {
+		return importPath
+	}
+	return strings.TrimPrefix(importPath, modPath+"/")
+}
+
+// pkgURL is where a package's page lives in the html site, relative to the site root. Pages mirror import paths,
+// so no two packages can share one.
+func pkgURL(importPath string) string {
+	var segments []string
+	for _, segment := range strings.Split(importPath, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			segment = "_"
+		}
+		segments = append(segments, segment)
+	}
+	return "pkg/" + strings.Join(segments, "/") + "/index.html"
+}
+
+// signature writes a func the way it is declared, such as 'func (UserService) GetUserByID(id int) (model.User, error)'
+func signature(function models.Func) string {
+	var params []string
+	for _, param := range function.Params {
+		params = append(params, strings.TrimSpace(param.Name+" "+param.Type))
+	}
+	var results []string
+	for _, ret := range function.Returns {
+		results = append(results, ret.Paren)
+	}
+
+	text := "func "
+	if function.Receiver != "" {
+		text += "(" + function.Receiver + ") "
+	}
+	text += function.Name + "(" + strings.Join(params, ", ") + ")"
+	if len(results) == 1 {
+		text += " " + results[0]
+	} else if len(results) > 1 {
+		text += " (" + strings.Join(results, ", ") + ")"
+	}
+	return text
+}
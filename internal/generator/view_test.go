@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// documentSlugs lists the slug of everything in a document that gets an anchor, in the order it is shown
+func documentSlugs(view DocumentView) []string {
+	var slugs []string
+	addVars := func(vars []VarView) {
+		for _, variable := range vars {
+			slugs = append(slugs, variable.Slug)
+		}
+	}
+	addFuncs := func(funcs []FuncView) {
+		for _, function := range funcs {
+			slugs = append(slugs, function.Slug)
+		}
+	}
+	addItems := func(types []TypeView, interfaces []InterfaceView, funcs []FuncView, vars []VarView) {
+		for _, _type := range types {
+			slugs = append(slugs, _type.Slug)
+			addVars(_type.Fields)
+			addFuncs(_type.Constructors)
+			addFuncs(_type.Methods)
+		}
+		for _, iface := range interfaces {
+			slugs = append(slugs, iface.Slug)
+			addFuncs(iface.Methods)
+		}
+		addFuncs(funcs)
+		addVars(vars)
+	}
+	for _, mod := range view.Modules {
+		for _, pkg := range mod.Packages {
+			slugs = append(slugs, pkg.Slug)
+			addItems(pkg.Types, pkg.Interfaces, pkg.Funcs, pkg.Vars)
+			for _, file := range pkg.Files {
+				slugs = append(slugs, file.Slug)
+				addItems(file.Types, file.Interfaces, file.Funcs, file.Vars)
+			}
+		}
+	}
+	return slugs
+}
+
+func TestFixtureSlugs(t *testing.T) {
+	settings := exampleSettings()
+	settings.IncludePrivateFuncs, settings.IncludePrivateVars, settings.IncludePrivateTypes = true, true, true
+	view := newDocumentView(settings, saveFixture(t, settings))
+
+	seen := make(map[string]bool)
+	for _, slug := range documentSlugs(view) {
+		if slug == "" || seen[slug] {
+			t.Errorf("slug %q is empty or repeated", slug)
+		}
+		seen[slug] = true
+	}
+	// Methods of the same name on types of the same name in different packages
+	for _, slug := range []string{"handler.UserHandler.GetAllUsers", "service.UserService.GetAllUsers", "handler.file-handler.go"} {
+		if !seen[slug] {
+			t.Errorf("no item has slug %q", slug)
+		}
+	}
+}
+
+func TestPkgSlugs(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages []models.Package
+		want     map[string]string
+	}{
+		{
+			name:     "unique names",
+			packages: []models.Package{{Name: "handler", ImportPath: "example.com/app/handler"}, {Name: "service", ImportPath: "example.com/app/service"}},
+			want:     map[string]string{"example.com/app/handler": "handler", "example.com/app/service": "service"},
+		},
+		{
+			name:     "shared name",
+			packages: []models.Package{{Name: "main", ImportPath: "example.com/app/cmd/api"}, {Name: "main", ImportPath: "example.com/app/cmd/cli"}, {Name: "api", ImportPath: "example.com/app/api"}},
+			want:     map[string]string{"example.com/app/cmd/api": "example.com-app-cmd-api", "example.com/app/cmd/cli": "example.com-app-cmd-cli", "example.com/app/api": "api"},
+		},
+		{
+			name:     "import paths slugified alike",
+			packages: []models.Package{{Name: "v", ImportPath: "example.com/a-b"}, {Name: "v", ImportPath: "example.com/a/b"}},
+			want:     map[string]string{"example.com/a-b": "example.com-a-b", "example.com/a/b": "example.com-a-b-2"},
+		},
+		{
+			name:     "no name",
+			packages: []models.Package{{ImportPath: "example.com/app"}},
+			want:     map[string]string{"example.com/app": "example.com-app"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := pkgSlugs([]models.Module{{Path: "example.com/app", Packages: test.packages}})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		function models.Func
+		want     string
+	}{
+		{function: models.Func{Name: "Open"}, want: "func Open()"},
+		{
+			function: models.Func{Name: "GetAllUsers", Receiver: "*UserService", Returns: []models.ReturnResponse{{Paren: "[]User"}, {Paren: "error"}}},
+			want:     "func (*UserService) GetAllUsers() ([]User, error)",
+		},
+		{
+			function: models.Func{Name: "Len", Receiver: "Store", Params: []models.Var{{Name: "n", Type: "int"}}, Returns: []models.ReturnResponse{{Paren: "int"}}},
+			want:     "func (Store) Len(n int) int",
+		},
+	}
+	for _, test := range tests {
+		if got := signature(test.function); got != test.want {
+			t.Errorf("signature(%+v) = %q, want %q", test.function, got, test.want)
+		}
+	}
+}
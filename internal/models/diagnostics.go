@@ -0,0 +1,121 @@
+package models
+
+import "fmt"
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Stable diagnostic codes. Codes are never reused, so CI configuration can rely on them
+const (
+	// Block and tag syntax
+	CodeUnknownTag      = "GD001" // Tag isn't valid for the block type
+	CodeUnknownHeader   = "GD002" // `-- HEADER` isn't a known block type
+	CodeMalformedHeader = "GD003" // Block doesn't start with `-- HEADER`
+	CodeMalformedTag    = "GD004" // Tag content doesn't follow the expected format
+	CodeBlankTag        = "GD005" // Tag has no name or no content
+	CodeEmptyBlock      = "GD006" // Block has no lines
+	CodeMalformedType   = "GD007" // (type) annotation isn't a valid Go type
+
+	// Project structure
+	CodePackageMismatch  = "GD010" // PKG block names a different package than the file declares
+	CodeDuplicatePackage = "GD011" // More than one PKG block for a package
+	CodeNoPackage        = "GD012" // FILE block in a package without a PKG block
+	CodeMissingName      = "GD013" // Block has no name and no declaration to take it from
+	CodeModule           = "GD014" // go.mod/go.work couldn't be read
+	CodeSettings         = "GD015" // settings.json has a value that can't be used
+
+	// Documentation compared with the Go source
+	CodeNoDeclaration = "GD020" // Documented item doesn't exist
+	CodeMismatch      = "GD021" // Documented name/type/receiver/count disagrees with the declaration
+	CodeUndocumented  = "GD022" // Declared param/field is missing from a block that documents the others
+	CodeGoSyntax      = "GD023" // Go source couldn't be parsed
+
+	// Reading and writing
+	CodeIO = "GD030" // File system or encoding failure
+
+	// Documentation generation
+	CodeNoPackages  = "GD040" // Nothing to generate
+	CodeEmptyOutput = "GD041" // Package has nothing to show for part of its output
+	CodeWriteFailed = "GD042" // Writing generated documentation failed
+)
+
+// CodeSummaries gives a short description of each code, for report formats that describe their rules
+var CodeSummaries = map[string]string{
+	CodeUnknownTag:       "Unknown tag for the block type",
+	CodeUnknownHeader:    "Unknown block header",
+	CodeMalformedHeader:  "Block doesn't start with a -- HEADER line",
+	CodeMalformedTag:     "Tag content isn't in the expected format",
+	CodeBlankTag:         "Tag with no name or content",
+	CodeEmptyBlock:       "Empty block",
+	CodeMalformedType:    "Type annotation isn't a valid Go type",
+	CodePackageMismatch:  "Package block names a different package than its file",
+	CodeDuplicatePackage: "More than one package block for a package",
+	CodeNoPackage:        "File block in a package with no package block",
+	CodeMissingName:      "Block has no name and no declaration below it",
+	CodeModule:           "go.mod/go.work couldn't be read",
+	CodeSettings:         "Setting can't be used",
+	CodeNoDeclaration:    "Documented item doesn't exist in the code",
+	CodeMismatch:         "Documentation disagrees with the declaration",
+	CodeUndocumented:     "Parameter or field left out of a block that documents the others",
+	CodeGoSyntax:         "Go source couldn't be parsed",
+	CodeIO:               "File couldn't be read or written",
+	CodeNoPackages:       "Nothing to generate",
+	CodeEmptyOutput:      "Package is missing part of its documentation",
+	CodeWriteFailed:      "Generated documentation couldn't be written",
+}
+
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Pos      Position
+	Fix      string // Suggested fix, if one is known
+}
+
+func (d Diagnostic) Error() string {
+	text := fmt.Sprintf("%s %s: %s", d.Severity, d.Code, d.Message)
+	if d.Pos.File != "" {
+		text = fmt.Sprintf("%s: %s", d.Pos, text)
+	}
+	return text
+}
+
+// Errorf creates an error-severity diagnostic
+func Errorf(code, format string, args ...any) Diagnostic {
+	return Diagnostic{Severity: SeverityError, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Warningf creates a warning-severity diagnostic
+func Warningf(code, format string, args ...any) Diagnostic {
+	return Diagnostic{Severity: SeverityWarning, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Infof creates an info-severity diagnostic
+func Infof(code, format string, args ...any) Diagnostic {
+	return Diagnostic{Severity: SeverityInfo, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (d Diagnostic) At(pos Position) Diagnostic {
+	d.Pos = pos
+	return d
+}
+
+func (d Diagnostic) WithFix(fix string) Diagnostic {
+	d.Fix = fix
+	return d
+}
+
+// HasErrors reports whether any of the diagnostics is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // Desc = Description
 
 type Settings struct {
@@ -7,28 +12,77 @@ type Settings struct {
 	ProjectDesc         string
 	ProjectPath         string
 	DocGenPath          string
-	DocGenFormat        string
+	DocGenFormat        Formats
 	IncludeTests        bool
-	IncludePrivateFuncs bool
-	IncludePrivateVars  bool
-	ExcludePackages     []string
+	IncludeDocComments  bool     // Fall back to standard // doc comments for anything without a GoDoc block
+	IncludePrivateFuncs bool     // Unexported funcs, methods and constructors
+	IncludePrivateVars  bool     // Unexported vars and constants
+	IncludePrivateTypes bool     // Unexported types and interfaces
+	ExcludePackages     []string // Import path globs of packages to leave out, '/...' also matches everything below
+	GOOS                string   // Platform to document, defaults to the one GoDoc runs on
+	GOARCH              string
+	BuildTags           []string // Extra build tags to consider set, such as 'cgo' or 'integration'
+	TemplateDir         string   // Directory of templates replacing the built-in ones, as <format>/<kind>.tmpl
+	TOCDepth            *int     // Levels the markdown table of contents lists, from 1 (packages) to 3 (methods), 0 to leave it out. 2 when unset.
+}
+
+// Formats lists output formats to generate. settings.json may give a single name instead of a list.
+type Formats []string
+
+func (f *Formats) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*f = nil
+		if single != "" {
+			*f = Formats{single}
+		}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("DocGenFormat must be a format name or a list of them")
+	}
+	*f = list
+	return nil
+}
+
+type Position struct {
+	File   string
+	Offset int // Byte offset, starting at 0
+	Line   int // Starting at 1
+	Column int // Byte column, starting at 1
 }
 
 type Comment struct {
-	File    string   `json:"file"`
-	Package string   `json:"package"`
-	Text    []string `json:"text"`
+	File       string     `json:"file"`
+	Package    string     `json:"package"`
+	ImportPath string     `json:"importPath"`
+	Pos        Position   `json:"pos"` // Where the block starts
+	Text       []string   `json:"text"`
+	Lines      []Position `json:"lines"` // Where each line of Text starts
+}
+
+type Module struct {
+	Path      string // Module path from go.mod, empty for packages outside any module
+	Dir       string
+	GoVersion string
+	Packages  []Package
 }
 
 type Package struct {
-	Name  string
-	Desc  string
-	Usage string
-	Files []File
-	Types []Type
-	Vars  []Var
-	Funcs []Func
-	Deps  []Dependency
+	Name       string
+	ImportPath string // Module-qualified, e.g. github.com/user/module/internal/handler
+	Dir        string
+	Desc       string
+	Usage      string
+	Files      []File
+	Types      []Type
+	Interfaces []Interface
+	Vars       []Var
+	Consts     []ConstGroup
+	Funcs      []Func
+	Deps       []Dependency
+	Pos        Position
 }
 
 type Dependency struct {
@@ -37,27 +91,69 @@ type Dependency struct {
 }
 
 type File struct {
-	Path    string
-	Name    string
-	Desc    string
-	Author  string
-	Version string
-	Date    string
-	Funcs   []Func
-	Vars    []Var
-	Types   []Type
+	Path       string
+	Name       string
+	Desc       string
+	Author     string
+	Version    string
+	Date       string
+	Build      string // Build constraint from the file's name and //go:build line, empty if it's built everywhere
+	Funcs      []Func
+	Vars       []Var
+	Consts     []ConstGroup
+	Types      []Type
+	Interfaces []Interface
+	Pos        Position
 }
 
 type Type struct {
-	Name   string
-	Desc   string
-	Fields []Var
+	Name         string
+	Desc         string
+	Fields       []Var
+	Constructors []Func   // Funcs that return the type
+	Methods      []Func   // Documented methods declared on the type
+	MethodSet    []Method // Every method declared on the type, documented or not
+	Pos          Position
+}
+
+type Interface struct {
+	Name         string
+	Desc         string
+	Methods      []Func
+	MethodSet    []Method // Every method the interface requires, including embedded ones. Empty if any couldn't be found.
+	Implementers []string // Documented types that implement the interface, qualified by package outside its own
+	Pos          Position
+}
+
+// Method is a method's name and signature, used to match types to the interfaces they implement
+type Method struct {
+	Name      string
+	Signature string // Parameter and result types, e.g. '(int) (model.User, error)', with the package's own types qualified
+	Pointer   bool   // Declared on a pointer receiver
 }
 
 type Var struct {
 	Name string
 	Type string
 	Desc string
+	Pos  Position
+}
+
+// ConstGroup is a const ( ... ) declaration documented as one unit, such as an enum
+type ConstGroup struct {
+	Name   string
+	Type   string // Type the constants belong to, if they share one declared in the package
+	Desc   string
+	Values []Const
+	Pos    Position
+}
+
+type Const struct {
+	Name  string
+	Type  string
+	Value string // Evaluated from the declaration, empty if it couldn't be worked out
+	Desc  string
+	Pos   Position
 }
 
 type Func struct {
@@ -67,6 +163,7 @@ type Func struct {
 	Returns   []ReturnResponse
 	Receiver  string
 	Responses []ReturnResponse
+	Pos       Position
 }
 
 type ReturnResponse struct {
@@ -77,4 +174,12 @@ type ReturnResponse struct {
 type Tag struct {
 	Name    string
 	Content string
+	Pos     Position
+}
+
+func (pos Position) String() string {
+	if pos.Line == 0 {
+		return pos.File
+	}
+	return fmt.Sprintf("%s:%d:%d", pos.File, pos.Line, pos.Column)
 }
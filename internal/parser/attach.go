@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+type declKind int
+
+const (
+	kindNone declKind = iota
+	kindFunc
+	kindType
+	kindVar
+)
+
+func blockKind(keyword string) declKind {
+	switch keyword {
+	case "FUNCTION", "FUNC":
+		return kindFunc
+	case "TYPE", "T", "INTERFACE", "IFACE":
+		return kindType
+	case "VARIABLE", "VAR", "V", "CONST", "ENUM":
+		return kindVar
+	}
+	return kindNone
+}
+
+// attachedDecl returns the declaration a block documents by position: the first declaration of the block's kind
+// that follows it, as long as no other block of the same kind sits in between.
+// Blocks inside a function body only attach to declarations in that body.
+func (p *Parser) attachedDecl(comment models.Comment, comments []models.Comment) ast.Node {
+	file, ok := p.files[comment.File]
+	if !ok {
+		return nil
+	}
+	keyword, err := extractKeyword(comment.Text[0])
+	if err != nil {
+		return nil
+	}
+	kind := blockKind(keyword)
+
+	var decl ast.Node
+	for _, candidate := range p.declsInScope(file, comment.Pos.Offset, kind) {
+		if p.offset(candidate.Pos()) > comment.Pos.Offset {
+			decl = candidate
+			break
+		}
+	}
+	if decl == nil {
+		return nil
+	}
+
+	// A later block of the same kind is closer to the declaration, so it owns it
+	for _, other := range comments {
+		if other.File != comment.File || other.Pos.Offset <= comment.Pos.Offset || other.Pos.Offset >= p.offset(decl.Pos()) {
+			continue
+		}
+		otherKeyword, err := extractKeyword(other.Text[0])
+		if err == nil && blockKind(otherKeyword) == kind {
+			return nil
+		}
+	}
+
+	return decl
+}
+
+// declsInScope lists declarations of a kind, in source order, from the scope that contains offset
+func (p *Parser) declsInScope(file *ast.File, offset int, kind declKind) []ast.Node {
+	var decls []ast.Node
+
+	// Blocks written inside a function body document that body's local declarations
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && fn.Body != nil && p.offset(fn.Body.Pos()) < offset && offset < p.offset(fn.Body.End()) {
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.GenDecl:
+					decls = append(decls, genDeclSpecs(n, kind)...)
+				case *ast.AssignStmt:
+					if kind == kindVar && n.Tok == token.DEFINE {
+						decls = append(decls, n)
+					}
+				}
+				return true
+			})
+			return decls
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if kind == kindFunc {
+				decls = append(decls, d)
+			}
+		case *ast.GenDecl:
+			decls = append(decls, genDeclSpecs(d, kind)...)
+		}
+	}
+	return decls
+}
+
+func genDeclSpecs(gen *ast.GenDecl, kind declKind) []ast.Node {
+	var specs []ast.Node
+	switch {
+	case kind == kindType && gen.Tok == token.TYPE:
+	case kind == kindVar && (gen.Tok == token.VAR || gen.Tok == token.CONST):
+	default:
+		return specs
+	}
+	for _, spec := range gen.Specs {
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func (p *Parser) offset(pos token.Pos) int {
+	return p.fset.Position(pos).Offset
+}
+
+// declName returns the identifier a declaration introduces, or the first one for multi-name declarations
+func declName(decl ast.Node) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Name.Name
+	case *ast.TypeSpec:
+		return d.Name.Name
+	case *ast.ValueSpec:
+		return d.Names[0].Name
+	case *ast.AssignStmt:
+		if ident, ok := d.Lhs[0].(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// declNames returns every identifier a var declaration introduces
+func declNames(decl ast.Node) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.ValueSpec:
+		for _, ident := range d.Names {
+			names = append(names, ident.Name)
+		}
+	case *ast.AssignStmt:
+		for _, lhs := range d.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				names = append(names, ident.Name)
+			}
+		}
+	default:
+		names = append(names, declName(decl))
+	}
+	return names
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"go/build"
+	"go/build/constraint"
+	"log"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// knownOS and knownArch are the GOOS and GOARCH values a filename suffix can name, as listed by go/build
+var knownOS = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux", "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos"}
+var knownArch = []string{"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips", "mipsle", "mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64", "s390", "s390x", "sparc", "sparc64", "wasm"}
+
+// unixOS are the GOOS values the 'unix' build tag is set for
+var unixOS = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "linux", "netbsd", "openbsd", "solaris"}
+
+// platform returns the GOOS and GOARCH to document, which default to the ones GoDoc runs on
+func (p *Parser) platform() (string, string) {
+	goos, goarch := p.settings.GOOS, p.settings.GOARCH
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos, goarch
+}
+
+// hasTag reports whether a build tag is satisfied for the platform being documented, the way the go command sets them
+func (p *Parser) hasTag(tag string) bool {
+	goos, goarch := p.platform()
+	switch {
+	case tag == goos || tag == goarch || tag == "gc":
+		return true
+	case tag == "unix":
+		return slices.Contains(unixOS, goos)
+	case tag == "linux":
+		return goos == "android"
+	case tag == "darwin":
+		return goos == "ios"
+	case tag == "solaris":
+		return goos == "illumos"
+	case strings.HasPrefix(tag, "go1."):
+		return slices.Contains(build.Default.ReleaseTags, tag)
+	}
+	return slices.Contains(p.settings.BuildTags, tag)
+}
+
+// dropOtherPlatforms leaves out the files whose filename suffix or //go:build line rules them out for the platform,
+// and records the constraint of every file that is kept so its docs can say which platforms it is built for
+func (p *Parser) dropOtherPlatforms(files []*sourceFile) []*sourceFile {
+	var kept []*sourceFile
+	for _, f := range files {
+		expr := fileConstraint(f.path, f.content)
+		if expr != nil && !expr.Eval(p.hasTag) {
+			log.Printf("Skipping file '%s', which isn't built for %s\n", f.path, strings.Join(p.platformTags(), ", "))
+			continue
+		}
+		if expr != nil {
+			p.builds[f.path] = expr.String()
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// platformTags lists what the platform being documented is, for log messages
+func (p *Parser) platformTags() []string {
+	goos, goarch := p.platform()
+	return append([]string{goos + "/" + goarch}, p.settings.BuildTags...)
+}
+
+// fileConstraint combines the constraint a file's name implies with its //go:build line, or returns nil if it has neither
+func fileConstraint(path, content string) constraint.Expr {
+	expr := nameConstraint(filepath.Base(path))
+	if line := buildLine(content); line != nil {
+		if expr == nil {
+			expr = line
+		} else {
+			expr = &constraint.AndExpr{X: line, Y: expr}
+		}
+	}
+	return expr
+}
+
+// nameConstraint returns what a name like 'file_linux.go', 'file_arm64.go' or 'file_windows_amd64_test.go' limits the file to
+func nameConstraint(name string) constraint.Expr {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".go"), "_test")
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return nil
+	}
+	last := parts[len(parts)-1]
+	if len(parts) >= 3 && slices.Contains(knownOS, parts[len(parts)-2]) && slices.Contains(knownArch, last) {
+		return &constraint.AndExpr{X: &constraint.TagExpr{Tag: parts[len(parts)-2]}, Y: &constraint.TagExpr{Tag: last}}
+	}
+	if slices.Contains(knownOS, last) || slices.Contains(knownArch, last) {
+		return &constraint.TagExpr{Tag: last}
+	}
+	return nil
+}
+
+// buildLine parses the //go:build line (or older // +build lines) in a file's header, before its package clause
+func buildLine(content string) constraint.Expr {
+	var plusBuild []constraint.Expr
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if !constraint.IsGoBuild(line) && !constraint.IsPlusBuild(line) {
+			continue
+		}
+		expr, err := constraint.Parse(line)
+		if err != nil {
+			// The go command rejects the file for this, and parsing reports it as a syntax error if it matters
+			continue
+		}
+		if constraint.IsGoBuild(line) {
+			return expr
+		}
+		plusBuild = append(plusBuild, expr)
+	}
+
+	// Each // +build line must hold
+	var expr constraint.Expr
+	for _, line := range plusBuild {
+		if expr == nil {
+			expr = line
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: line}
+		}
+	}
+	return expr
+}
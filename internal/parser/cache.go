@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sync"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+const cacheFile = "./godoc_cache.json"
+
+// buildID identifies the GoDoc binary doing the save. Any rebuild that could parse the same source differently,
+// or change what the cache stores, gives a new ID, so caches written by other builds are thrown away.
+var buildID = sync.OnceValue(func() string {
+	if exe, err := os.Executable(); err == nil {
+		if data, err := os.ReadFile(exe); err == nil {
+			sum := sha256.Sum256(data)
+			return hex.EncodeToString(sum[:])
+		}
+	}
+	// Without the binary itself, the module version and VCS revision it was built from are the next best thing
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	id := info.Main.Path + "@" + info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+			id += " " + setting.Value
+		}
+	}
+	return id
+})
+
+// cache remembers what each file and directory produced on the last save, so unchanged ones can be skipped
+type cache struct {
+	Key   string                // Hash of the build, settings and modules the cache was built with
+	Files map[string]cachedFile // By file path
+	Dirs  map[string]cachedDir  // By directory
+}
+
+type cachedFile struct {
+	Hash        string // SHA-256 of the file's content
+	Comments    []models.Comment
+	Diagnostics []models.Diagnostic // Problems found while reading the file
+}
+
+type cachedDir struct {
+	Files       []string // Every file read from the directory, in walk order
+	Packages    []models.Package
+	Diagnostics []models.Diagnostic // Problems found while putting the directory's package together
+}
+
+func newCache(key string) cache {
+	return cache{
+		Key:   key,
+		Files: make(map[string]cachedFile),
+		Dirs:  make(map[string]cachedDir),
+	}
+}
+
+// cacheKey hashes everything besides the source that decides what a save produces.
+// Rebuilding GoDoc or changing any setting or go.mod throws the whole cache away.
+func (p *Parser) cacheKey() string {
+	data, _ := json.Marshal(struct {
+		Build    string
+		Settings models.Settings
+		Modules  []models.Module
+	}{buildID(), p.settings, p.modules})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCache reads the cache from the last save, or starts an empty one if it is missing, out of date or Force is set
+func (p *Parser) loadCache(key string) cache {
+	if p.Force {
+		return newCache(key)
+	}
+
+	data, err := os.ReadFile(cacheFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return newCache(key)
+	}
+	if err != nil {
+		p.report(models.Warningf(models.CodeIO, "could not read cache, reading every file: %v", err), models.Position{File: cacheFile})
+		return newCache(key)
+	}
+
+	var previous cache
+	if err := json.Unmarshal(data, &previous); err != nil {
+		p.report(models.Warningf(models.CodeIO, "could not decode cache, reading every file: %v", err).WithFix("delete the cache file or run with -force"), models.Position{File: cacheFile})
+		return newCache(key)
+	}
+	if previous.Key != key || previous.Files == nil || previous.Dirs == nil {
+		log.Printf("GoDoc, settings or modules changed since the last save, reading every file")
+		return newCache(key)
+	}
+	return previous
+}
+
+// cachedFile returns what a file produced last time, if its content hasn't changed since
+func (c cache) cachedFile(f *sourceFile) (cachedFile, bool) {
+	cached, ok := c.Files[f.path]
+	if !ok || f.hash == "" || cached.Hash != f.hash {
+		return cachedFile{}, false
+	}
+	return cached, true
+}
+
+// unchanged reports whether a directory has exactly the same files, with the same content, as last time
+func (c cache) unchanged(dir *sourceDir) bool {
+	cached, ok := c.Dirs[dir.path]
+	if !ok || len(cached.Files) != len(dir.files) {
+		return false
+	}
+	for i, f := range dir.files {
+		if cached.Files[i] != f.path {
+			return false
+		}
+		if _, ok := c.cachedFile(f); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c cache) save() error {
+	file, err := os.Create(cacheFile)
+	if err != nil {
+		return fmt.Errorf("error creating cache file: %v", err)
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(c); err != nil {
+		return fmt.Errorf("error encoding cache: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"strconv"
+	"unicode"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// parseConstGroup reads a CONST block, which documents a whole const ( ... ) group at once.
+// Each '@value' describes one of the group's constants.
+func (p *Parser) parseConstGroup(comment models.Comment, comments []models.Comment) {
+	var group models.ConstGroup
+	group.Pos = comment.Pos
+	var documented []models.Var
+	tags, err := extractCommentTags(comment)
+	if err != nil {
+		p.report(err, comment.Pos)
+	} else {
+		for _, tag := range tags {
+			if tag.Name == "const" || tag.Name == "enum" || tag.Name == "name" {
+				group.Name = tag.Content
+			} else if tag.Name == "description" || tag.Name == "desc" || tag.Name == "brief" {
+				group.Desc = tag.Content
+			} else if tag.Name == "type" || tag.Name == "t" {
+				group.Type = tag.Content
+			} else if tag.Name == "value" || tag.Name == "val" {
+				value, err := extractVarContent(tag.Content, contentPosition(tag))
+				if err != nil {
+					p.report(err, tag.Pos)
+				} else {
+					value.Pos = tag.Pos
+					documented = append(documented, value)
+				}
+			} else {
+				p.report(models.Errorf(models.CodeUnknownTag, "tag name '%s' unrecognized for const declaration", tag.Name).WithFix("use one of @const, @description, @type or @value"), tag.Pos)
+			}
+		}
+	}
+
+	p.resolveConstGroup(&group, documented, comment, comments)
+	if group.Name == "" {
+		p.report(models.Errorf(models.CodeMissingName, "no name given for const block and no declaration follows it").WithFix("add a '@const name' tag or move the block directly above its declaration"), comment.Pos)
+		return
+	}
+	if unicode.IsUpper(rune(group.Name[0])) {
+		// Exported, belongs to pkg
+		for i := range p.Packages {
+			if p.Packages[i].ImportPath == comment.ImportPath {
+				p.Packages[i].Consts = append(p.Packages[i].Consts, group)
+			}
+		}
+	} else {
+		// Unexported, belongs to file
+		for i, pkg := range p.Packages {
+			for j := range pkg.Files {
+				if pkg.Files[j].Path == comment.File {
+					p.Packages[i].Files[j].Consts = append(p.Packages[i].Files[j].Consts, group)
+				}
+			}
+		}
+	}
+}
+
+// resolveConstGroup finds the const declaration a CONST block documents and fills in every constant in it,
+// with its type and value worked out by the type checker
+func (p *Parser) resolveConstGroup(group *models.ConstGroup, documented []models.Var, comment models.Comment, comments []models.Comment) {
+	where := comment.Pos
+	files := p.packageFiles(comment.File)
+	if len(files) == 0 {
+		return
+	}
+
+	gen := constDecl(files, p.attachedDecl(comment, comments))
+	if gen == nil && group.Name != "" {
+		// The block isn't directly above its constants, fall back to looking the group up by one of its names
+		gen = constDecl(files, findVar(files, group.Name))
+	}
+	if gen == nil {
+		if group.Name != "" {
+			p.report(models.Errorf(models.CodeNoDeclaration, "const group '%s' is documented but no matching declaration exists", group.Name).WithFix("move the block directly above its const ( ... ) declaration"), where)
+		}
+		return
+	}
+
+	// List what is declared, with the values the type checker worked out
+	info := p.typeCheck(comment.ImportPath, files)
+	var declared []models.Var
+	values := make(map[string]string)
+	lineComments := make(map[string]string)
+	for _, spec := range gen.Specs {
+		vs := spec.(*ast.ValueSpec)
+		for _, ident := range vs.Names {
+			if ident.Name == "_" {
+				continue
+			}
+			typeName := ""
+			if obj, ok := info.Defs[ident].(*types.Const); ok {
+				if value := constValue(obj); value != "" {
+					values[ident.Name] = value
+				}
+				if basic, ok := obj.Type().(*types.Basic); obj.Type() != types.Typ[types.Invalid] && !(ok && basic.Info()&types.IsUntyped != 0) {
+					typeName = types.TypeString(obj.Type(), func(other *types.Package) string {
+						if other == obj.Pkg() {
+							return ""
+						}
+						return other.Name()
+					})
+				}
+			}
+			if typeName == "" && vs.Type != nil {
+				typeName = types.ExprString(vs.Type)
+			}
+			declared = append(declared, models.Var{Name: ident.Name, Type: typeName, Pos: p.declPosition(ident.Pos())})
+			lineComments[ident.Name] = docText(vs.Doc.Text() + vs.Comment.Text())
+		}
+	}
+
+	// The group belongs to the type its constants share, if the package declares it
+	shared := ""
+	for i, value := range declared {
+		if i > 0 && value.Type != shared {
+			shared = ""
+			break
+		}
+		shared = value.Type
+	}
+	if shared != "" && findTypeSpec(files, shared) == nil {
+		shared = ""
+	}
+	if group.Type != "" && shared != "" && !sameType(group.Type, shared) {
+		p.report(models.Warningf(models.CodeMismatch, "const group documents type '%s' but its constants are '%s'", group.Type, shared).WithFix(fmt.Sprintf("change the documented type to '%s' or leave it out", shared)), where)
+	}
+	if shared != "" {
+		group.Type = shared
+	}
+	if group.Name == "" {
+		group.Name = group.Type
+	}
+	if group.Name == "" && len(declared) > 0 {
+		group.Name = declared[0].Name
+	}
+
+	group.Values = nil
+	for _, merged := range p.mergeVars(documented, declared, where, fmt.Sprintf("const group '%s'", group.Name), "value") {
+		if merged.Desc == "" {
+			// Fall back to the constant's own // comment
+			merged.Desc = lineComments[merged.Name]
+		}
+		group.Values = append(group.Values, models.Const{Name: merged.Name, Type: merged.Type, Value: values[merged.Name], Desc: merged.Desc, Pos: merged.Pos})
+	}
+}
+
+// constDecl returns the const ( ... ) declaration a spec belongs to
+func constDecl(files []*ast.File, node ast.Node) *ast.GenDecl {
+	spec, ok := node.(*ast.ValueSpec)
+	if !ok {
+		return nil
+	}
+	var found *ast.GenDecl
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if gen, ok := node.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+				for _, s := range gen.Specs {
+					if s == spec {
+						found = gen
+					}
+				}
+			}
+			return found == nil
+		})
+	}
+	return found
+}
+
+// typeCheck runs the type checker over a package's files, once per directory. Imports are stubbed out,
+// so everything that depends on another package stays unresolved and its errors are ignored.
+func (p *Parser) typeCheck(importPath string, files []*ast.File) *types.Info {
+	dir := filepath.Dir(p.fset.Position(files[0].Package).Filename)
+	if info, ok := p.typeInfo[dir]; ok {
+		return info
+	}
+
+	// Only the files of the package itself, not its external tests
+	var pkgFiles []*ast.File
+	for _, file := range files {
+		if file.Name.Name == files[0].Name.Name {
+			pkgFiles = append(pkgFiles, file)
+		}
+	}
+
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	config := types.Config{Importer: stubImporter{}, Error: func(error) {}}
+	config.Check(importPath, p.fset, pkgFiles, info)
+	p.typeInfo[dir] = info
+	return info
+}
+
+// stubImporter stands in for every import while type-checking. Each import is an empty package.
+type stubImporter struct{}
+
+func (stubImporter) Import(importPath string) (*types.Package, error) {
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// constValue writes a constant's value the way it would be written in Go: '2.5' rather than the exact fraction '5/2',
+// and runes as quoted characters. It is empty if the value couldn't be worked out.
+func constValue(obj *types.Const) string {
+	value := obj.Val()
+	switch value.Kind() {
+	case constant.Unknown:
+		return ""
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case constant.Int:
+		if basic, ok := obj.Type().(*types.Basic); ok && (basic.Kind() == types.UntypedRune || basic.Name() == "rune") {
+			if r, exact := constant.Int64Val(value); exact && r >= 0 && r <= unicode.MaxRune {
+				return strconv.QuoteRune(rune(r))
+			}
+		}
+	}
+	return value.ExactString()
+}
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"errors"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// report records a problem found at pos
+func (p *Parser) report(err error, pos models.Position) {
+	p.Diagnostics = append(p.Diagnostics, diagnosticFor(err, pos))
+}
+
+// diagnosticFor turns an error into a diagnostic at pos. Errors that are already diagnostics keep their severity, code and fix,
+// anything else is treated as an I/O error
+func diagnosticFor(err error, pos models.Position) models.Diagnostic {
+	var diag models.Diagnostic
+	if !errors.As(err, &diag) {
+		diag = models.Errorf(models.CodeIO, "%v", err)
+	}
+	if diag.Pos.File == "" {
+		diag.Pos = pos
+	}
+	return diag
+}
+
+// atPosition places a diagnostic at pos, unless it already has a position of its own
+func atPosition(err error, pos models.Position) error {
+	var diag models.Diagnostic
+	if errors.As(err, &diag) && diag.Pos.File == "" {
+		return diag.At(pos)
+	}
+	return err
+}
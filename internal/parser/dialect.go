@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"go/ast"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// The Doxygen/Javadoc dialect is written in /** blocks with no '-- HEADER' line. Text before the first tag
+// (or an @brief tag) is the description, and the block documents whatever declaration follows it.
+// Blocks are translated into ordinary GoDoc lines as they are read, so everything after reading works the same.
+
+// doxygenTags are the tags that make a /** comment a Doxygen block. Others, such as '@license' in a copyright
+// header, are common in plain comments too.
+var doxygenTags = []string{"brief", "short", "details", "param", "return", "returns", "file", "fn", "class", "struct", "typedef", "interface"}
+
+// dialectTags are the tags each block type reads once translated. Doxygen blocks often carry tags GoDoc has no use for,
+// such as '@since' or '@see', so anything else is left out with a warning rather than failing the save.
+var dialectTags = map[string][]string{
+	"PKG":  {"package", "pkg", "p", "name", "description", "desc", "usage", "u", "dependency", "dep"},
+	"FILE": {"file", "f", "name", "description", "desc", "author", "auth", "a", "version", "v", "date", "d"},
+	"FUNC": {"function", "func", "f", "name", "description", "desc", "receiver", "rec", "parameter", "param", "p", "return", "returns", "ret", "response", "res"},
+	"TYPE": {"type", "t", "name", "description", "desc", "field", "f"},
+	"VAR":  {"variable", "var", "v", "name", "description", "desc", "type", "t"},
+}
+
+// isCommentAt reports whether a real Go comment, rather than part of a string, starts at offset
+func (p *Parser) isCommentAt(f *sourceFile, offset int) bool {
+	if f.ast == nil {
+		return false
+	}
+	for _, group := range f.ast.Comments {
+		for _, c := range group.List {
+			if p.offset(c.Pos()) == offset {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Parser) extractDoxygenComment(f *sourceFile, l *lexer, pkgName string) models.Comment {
+	pos := f.positionAt(l.position)
+	l.advanceBy(3) // Skip /**
+	lines, linePositions := f.readBlockLines(l)
+
+	// Plain /** comments without any Doxygen tags, such as copyright headers, aren't meant for GoDoc
+	if !hasDoxygenTag(lines) {
+		return models.Comment{}
+	}
+
+	keyword := p.doxygenKeyword(f, lines, l.position)
+	if keyword == "" {
+		f.report(models.Warningf(models.CodeMissingName, "no declaration follows this Doxygen block").WithFix("move the block directly above the declaration it documents, or add '@file' to document the file"), pos)
+		return models.Comment{}
+	}
+	// The file's own PKG block documents the package
+	if keyword == "PKG" && hasPackageBlock(f.content) {
+		return models.Comment{}
+	}
+	lines, linePositions = f.translateDoxygen(lines, linePositions, keyword, pos)
+
+	return models.Comment{
+		File:       f.path,
+		Package:    pkgName,
+		ImportPath: f.importPath,
+		Pos:        pos,
+		Text:       lines,
+		Lines:      linePositions,
+	}
+}
+
+func hasDoxygenTag(lines []string) bool {
+	for _, line := range lines {
+		if name, _ := doxygenTag(line); slices.Contains(doxygenTags, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// doxygenTag splits a line starting with a tag into its name and content. '@param[in]' is read as '@param'.
+func doxygenTag(line string) (string, string) {
+	if !strings.HasPrefix(line, "@") {
+		return "", ""
+	}
+	name, content, _ := strings.Cut(line[1:], " ")
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name), strings.TrimSpace(content)
+}
+
+// doxygenKeyword works out the block type a Doxygen block stands for: FILE if it has @file,
+// otherwise whatever comes first after end, in the same scope: the package clause, a func, a type or a var
+func (p *Parser) doxygenKeyword(f *sourceFile, lines []string, end int) string {
+	for _, line := range lines {
+		if name, _ := doxygenTag(line); name == "file" {
+			return "FILE"
+		}
+	}
+
+	keyword, next := "", -1
+	consider := func(node ast.Node, candidate string) {
+		if offset := p.offset(node.Pos()); offset >= end && (next == -1 || offset < next) {
+			keyword, next = candidate, offset
+		}
+	}
+
+	if !p.inFuncBody(f.ast, end) && p.offset(f.ast.Package) >= end {
+		return "PKG"
+	}
+	for kind, candidate := range map[declKind]string{kindFunc: "FUNC", kindType: "TYPE", kindVar: "VAR"} {
+		for _, decl := range p.declsInScope(f.ast, end, kind) {
+			consider(decl, candidate)
+		}
+	}
+	return keyword
+}
+
+func (p *Parser) inFuncBody(file *ast.File, offset int) bool {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && fn.Body != nil && p.offset(fn.Body.Pos()) < offset && offset < p.offset(fn.Body.End()) {
+			return true
+		}
+	}
+	return false
+}
+
+// translateDoxygen rewrites a Doxygen block as GoDoc lines: a '-- KEYWORD' header, an @desc made of the
+// @brief and the untagged text, and the tags, renamed where GoDoc calls them something else.
+func (f *sourceFile) translateDoxygen(lines []string, positions []models.Position, keyword string, pos models.Position) ([]string, []models.Position) {
+	var brief, desc, tags []string
+	var briefPos, descPos, tagPos []models.Position
+	current := "desc" // Where untagged lines go: "brief", "desc", "tag" for the last tag's content, or "skip" for an unknown tag's
+
+	for i, line := range lines {
+		name, content := doxygenTag(line)
+		switch {
+		case name == "brief" || name == "short":
+			brief, briefPos = append(brief, content), append(briefPos, positions[i])
+			current = "brief"
+			continue
+		case name == "details":
+			desc, descPos = append(desc, content), append(descPos, positions[i])
+			current = "desc"
+			continue
+		case name == "fn" || name == "class" || name == "struct" || name == "typedef" || name == "interface":
+			line = "@name " + content
+		case name == "file" && content == "":
+			line = "@file " + filepath.Base(f.path)
+		case name == "returns":
+			line = "@return " + content
+		case name == "param":
+			line = "@param " + content
+		case name != "" && !slices.Contains(dialectTags[keyword], name):
+			f.report(models.Warningf(models.CodeUnknownTag, "tag name '%s' isn't read in Doxygen blocks and is left out", name), positions[i])
+			current = "skip"
+			continue
+		case name != "":
+		case current == "skip":
+			continue
+		case current == "brief" && line == "":
+			// A brief description ends at the first blank line
+			current = "desc"
+			continue
+		case current == "brief":
+			brief, briefPos = append(brief, line), append(briefPos, positions[i])
+			continue
+		case current == "desc":
+			desc, descPos = append(desc, line), append(descPos, positions[i])
+			continue
+		}
+		if name != "" {
+			current = "tag"
+		}
+		tags, tagPos = append(tags, line), append(tagPos, positions[i])
+	}
+
+	// The brief description leads, followed by the detailed one as its own paragraph
+	if len(brief) > 0 && len(strings.TrimSpace(strings.Join(desc, ""))) > 0 {
+		brief, briefPos = append(brief, ""), append(briefPos, briefPos[len(briefPos)-1])
+	}
+	desc, descPos = append(brief, desc...), append(briefPos, descPos...)
+	for len(desc) > 0 && desc[0] == "" {
+		desc, descPos = desc[1:], descPos[1:]
+	}
+	for len(desc) > 0 && desc[len(desc)-1] == "" {
+		desc, descPos = desc[:len(desc)-1], descPos[:len(descPos)-1]
+	}
+
+	translated := []string{"-- " + keyword}
+	translatedPos := []models.Position{pos}
+	if len(desc) > 0 {
+		desc[0] = "@desc " + desc[0]
+		translated, translatedPos = append(translated, desc...), append(translatedPos, descPos...)
+	}
+	return append(translated, tags...), append(translatedPos, tagPos...)
+}
+
+// hasPackageBlock reports whether a file has a GoDoc PKG block, which always takes precedence over a Doxygen block
+// above the package clause, such as a copyright or overview comment
+func hasPackageBlock(content string) bool {
+	for _, block := range strings.Split(content, "/***")[1:] {
+		header, _, _ := strings.Cut(strings.TrimLeft(block, " \t\r\n*"), "\n")
+		keyword, err := extractKeyword(header)
+		if err == nil && (keyword == "PACKAGE" || keyword == "PKG" || keyword == "P") {
+			return true
+		}
+	}
+	return false
+}
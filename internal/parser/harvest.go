@@ -0,0 +1,348 @@
+package parser
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/doc/comment"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// harvestDocComments fills in packages, types, funcs and vars from the standard // doc comments of the given files
+// wherever no GoDoc block documents them. Blocks always take precedence.
+func (p *Parser) harvestDocComments(filePaths []string) {
+	// Group the parsed files by directory and package name, in a stable order. Test files are only here when IncludeTests is set.
+	var paths []string
+	for _, path := range filePaths {
+		if _, ok := p.files[path]; ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	type pkgFiles struct {
+		name  string
+		paths []string
+		files []*ast.File
+	}
+	var groups []*pkgFiles
+	for _, path := range paths {
+		file := p.files[path]
+		var group *pkgFiles
+		for _, g := range groups {
+			if g.name == file.Name.Name && filepath.Dir(g.paths[0]) == filepath.Dir(path) {
+				group = g
+			}
+		}
+		if group == nil {
+			group = &pkgFiles{name: file.Name.Name}
+			groups = append(groups, group)
+		}
+		group.paths = append(group.paths, path)
+		group.files = append(group.files, file)
+	}
+
+	fset := sourceFileSet(p.fset)
+	for _, group := range groups {
+		importPath := p.importPathFor(filepath.Dir(group.paths[0]))
+		docPkg, err := doc.NewFromFiles(fset, group.files, importPath, doc.PreserveAST)
+		if err != nil {
+			p.report(models.Warningf(models.CodeGoSyntax, "could not read doc comments: %v", err), models.Position{File: group.paths[0]})
+			continue
+		}
+		p.harvestPackage(docPkg, group.paths, group.files)
+	}
+}
+
+// sourceFileSet copies the files of fset, at the same positions, under names that don't end in _test.go.
+// doc.NewFromFiles tells test files apart by name and only looks in them for examples, while their doc comments
+// are wanted here like any other file's.
+func sourceFileSet(fset *token.FileSet) *token.FileSet {
+	renamed := token.NewFileSet()
+	fset.Iterate(func(f *token.File) bool {
+		name := f.Name()
+		if strings.HasSuffix(name, "_test.go") {
+			name = strings.TrimSuffix(name, ".go") + ".src.go"
+		}
+		renamed.AddFile(name, f.Base(), f.Size())
+		return true
+	})
+	return renamed
+}
+
+func (p *Parser) harvestPackage(docPkg *doc.Package, paths []string, files []*ast.File) {
+	var harvested models.Package
+
+	for _, t := range docPkg.Types {
+		if text := docText(t.Doc); text != "" {
+			_type := models.Type{Name: t.Name, Desc: text, MethodSet: typeMethodSet(files, t.Name, docPkg.ImportPath), Pos: p.declPosition(t.Decl.Pos())}
+			var iface *models.Interface
+			for _, spec := range t.Decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == t.Name {
+					if structType, ok := ts.Type.(*ast.StructType); ok {
+						_type.Fields = p.fieldVars(structType.Fields)
+					}
+					if interfaceType, ok := ts.Type.(*ast.InterfaceType); ok {
+						iface = p.harvestInterface(_type, interfaceType, typeParamNames(ts.TypeParams), files, docPkg.ImportPath)
+					}
+				}
+			}
+			if iface != nil {
+				harvested.Interfaces = append(harvested.Interfaces, *iface)
+			} else {
+				harvested.Types = append(harvested.Types, _type)
+			}
+		}
+		// Constructors, methods and grouped values are listed under their type by go/doc
+		harvested.Funcs = append(harvested.Funcs, p.harvestFuncs(t.Funcs)...)
+		harvested.Funcs = append(harvested.Funcs, p.harvestFuncs(t.Methods)...)
+		harvested.Vars = append(harvested.Vars, p.harvestValues(t.Consts)...)
+		harvested.Vars = append(harvested.Vars, p.harvestValues(t.Vars)...)
+	}
+	harvested.Funcs = append(harvested.Funcs, p.harvestFuncs(docPkg.Funcs)...)
+	harvested.Vars = append(harvested.Vars, p.harvestValues(docPkg.Consts)...)
+	harvested.Vars = append(harvested.Vars, p.harvestValues(docPkg.Vars)...)
+
+	// Find the package a PKG block already declared, or start one from the package doc comment
+	index := -1
+	for i := range p.Packages {
+		if p.Packages[i].ImportPath == docPkg.ImportPath {
+			index = i
+		}
+	}
+	if index == -1 {
+		if docText(docPkg.Doc) == "" && len(harvested.Types) == 0 && len(harvested.Interfaces) == 0 && len(harvested.Funcs) == 0 && len(harvested.Vars) == 0 {
+			return
+		}
+		pkg := models.Package{
+			Name:       docPkg.Name,
+			ImportPath: docPkg.ImportPath,
+			Dir:        filepath.Dir(paths[0]),
+			Desc:       docText(docPkg.Doc),
+			Pos:        p.declPosition(files[0].Package),
+		}
+		for i, path := range paths {
+			pkg.Files = append(pkg.Files, models.File{Path: path, Name: filepath.Base(path), Pos: p.declPosition(files[i].Package)})
+		}
+		p.Packages = append(p.Packages, pkg)
+		index = len(p.Packages) - 1
+	}
+	pkg := &p.Packages[index]
+
+	for _, _type := range harvested.Types {
+		if !hasType(pkg, _type.Name) {
+			pkg.Types = append(pkg.Types, _type)
+		}
+	}
+	for _, iface := range harvested.Interfaces {
+		if !hasType(pkg, iface.Name) {
+			pkg.Interfaces = append(pkg.Interfaces, iface)
+		}
+	}
+	for _, function := range harvested.Funcs {
+		if !hasFunc(pkg, function.Name, trimPointer(function.Receiver)) {
+			pkg.Funcs = append(pkg.Funcs, function)
+		}
+	}
+	for _, variable := range harvested.Vars {
+		if !hasVar(pkg, variable.Name) {
+			pkg.Vars = append(pkg.Vars, variable)
+		}
+	}
+}
+
+// hasType reports whether a package already documents a type or interface
+func hasType(pkg *models.Package, name string) bool {
+	for _, _type := range pkg.Types {
+		if _type.Name == name {
+			return true
+		}
+	}
+	for _, iface := range pkg.Interfaces {
+		if iface.Name == name {
+			return true
+		}
+	}
+	for _, file := range pkg.Files {
+		for _, _type := range file.Types {
+			if _type.Name == name {
+				return true
+			}
+		}
+		for _, iface := range file.Interfaces {
+			if iface.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// harvestInterface documents an interface from its doc comment and the doc comments of its methods
+func (p *Parser) harvestInterface(_type models.Type, interfaceType *ast.InterfaceType, typeParams []string, files []*ast.File, pkgPath string) *models.Interface {
+	iface := &models.Interface{Name: _type.Name, Desc: _type.Desc, Pos: _type.Pos}
+	for _, field := range interfaceType.Methods.List {
+		fnType, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			continue
+		}
+		method := models.Func{
+			Name:   field.Names[0].Name,
+			Desc:   docText(field.Doc.Text()),
+			Params: p.fieldVars(fnType.Params),
+			Pos:    p.declPosition(field.Pos()),
+		}
+		for _, result := range p.fieldVars(fnType.Results) {
+			method.Returns = append(method.Returns, models.ReturnResponse{Paren: result.Type})
+		}
+		iface.Methods = append(iface.Methods, method)
+	}
+	iface.MethodSet = interfaceMethodSet(files, interfaceType, pkgPath, typeParams, map[string]bool{iface.Name: true})
+	return iface
+}
+
+func hasFunc(pkg *models.Package, name, receiver string) bool {
+	for _, function := range pkg.Funcs {
+		if function.Name == name && trimPointer(function.Receiver) == receiver {
+			return true
+		}
+	}
+	for _, file := range pkg.Files {
+		for _, function := range file.Funcs {
+			if function.Name == name && trimPointer(function.Receiver) == receiver {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasVar(pkg *models.Package, name string) bool {
+	for _, variable := range pkg.Vars {
+		if variable.Name == name {
+			return true
+		}
+	}
+	for _, file := range pkg.Files {
+		for _, variable := range file.Vars {
+			if variable.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Parser) harvestFuncs(funcs []*doc.Func) []models.Func {
+	var harvested []models.Func
+	for _, f := range funcs {
+		text := docText(f.Doc)
+		if text == "" {
+			continue
+		}
+		function := models.Func{
+			Name:     f.Name,
+			Desc:     text,
+			Receiver: receiverType(f.Decl),
+			Params:   p.fieldVars(f.Decl.Type.Params),
+			Pos:      p.declPosition(f.Decl.Pos()),
+		}
+		for _, result := range p.fieldVars(f.Decl.Type.Results) {
+			function.Returns = append(function.Returns, models.ReturnResponse{Paren: result.Type})
+		}
+		harvested = append(harvested, function)
+	}
+	return harvested
+}
+
+// harvestValues splits var/const groups into one entry per name, preferring a spec's own comment over the group's
+func (p *Parser) harvestValues(values []*doc.Value) []models.Var {
+	var harvested []models.Var
+	for _, value := range values {
+		for _, spec := range value.Decl.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			text := docText(value.Doc)
+			if vs.Doc != nil {
+				text = docText(vs.Doc.Text())
+			}
+			if text == "" {
+				continue
+			}
+			varType := ""
+			if vs.Type != nil {
+				varType = types.ExprString(vs.Type)
+			}
+			for _, ident := range vs.Names {
+				if ident.IsExported() {
+					harvested = append(harvested, models.Var{Name: ident.Name, Type: varType, Desc: text, Pos: p.declPosition(ident.Pos())})
+				}
+			}
+		}
+	}
+	return harvested
+}
+
+// docText lays a doc comment out the same way as tag content (see formatText),
+// ignoring GoDoc blocks that go/doc picked up as regular comments
+func docText(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "**") {
+		return ""
+	}
+
+	var parser comment.Parser
+	var blocks []string
+	for _, block := range parser.Parse(text).Content {
+		switch b := block.(type) {
+		case *comment.Paragraph:
+			blocks = append(blocks, docInline(b.Text))
+		case *comment.Heading:
+			blocks = append(blocks, docInline(b.Text))
+		case *comment.Code:
+			blocks = append(blocks, "```\n"+strings.TrimSuffix(b.Text, "\n")+"\n```")
+		case *comment.List:
+			var items []string
+			for _, item := range b.Items {
+				marker := "-"
+				if item.Number != "" {
+					marker = item.Number + "."
+				}
+				var text []string
+				for _, content := range item.Content {
+					if paragraph, ok := content.(*comment.Paragraph); ok {
+						text = append(text, docInline(paragraph.Text))
+					}
+				}
+				items = append(items, marker+" "+strings.Join(text, " "))
+			}
+			blocks = append(blocks, strings.Join(items, "\n"))
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// docInline flattens the text of a doc comment paragraph, joining wrapped lines
+func docInline(text []comment.Text) string {
+	var sb strings.Builder
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			sb.WriteString(string(t))
+		case comment.Italic:
+			sb.WriteString(string(t))
+		case *comment.Link:
+			sb.WriteString(docInline(t.Text))
+		case *comment.DocLink:
+			sb.WriteString(docInline(t.Text))
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
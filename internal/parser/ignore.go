@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+const ignoreFile = ".godocignore"
+
+// defaultIgnores are skipped in every project, as if they were at the top of the root .godocignore.
+// A '!' pattern in a .godocignore brings them back.
+var defaultIgnores = []string{"vendor/", "testdata/", ".*"}
+
+// ignoreRule is a single .godocignore pattern
+type ignoreRule struct {
+	base    string // Directory of the .godocignore, relative to the walk root, "" for the root itself
+	pattern *regexp.Regexp
+	negate  bool // '!pattern' includes again what an earlier pattern ignored
+	dirOnly bool // 'pattern/' only matches directories
+}
+
+// ignoreRules holds the patterns of every .godocignore found so far, parents before their subdirectories
+type ignoreRules []ignoreRule
+
+// match reports whether a path relative to the walk root is ignored, and whether any pattern matched it at all.
+// The last matching pattern wins, the same as in .gitignore.
+func (rules ignoreRules) match(rel string, isDir bool) (ignored, matched bool) {
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, rule.base+"/")
+		}
+		if rule.pattern.MatchString(sub) {
+			ignored, matched = !rule.negate, true
+		}
+	}
+	return ignored, matched
+}
+
+// add appends the rules of one .godocignore, given as lines, found in the directory base.
+// A pattern that can't be translated is left out and reported at its line of file.
+func (rules ignoreRules) add(base, file string, lines []string) (ignoreRules, []models.Diagnostic) {
+	var diagnostics []models.Diagnostic
+	for i, line := range lines {
+		pos := models.Position{File: file, Line: i + 1, Column: 1}
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		rule.base = base
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// A slash anywhere but the end anchors the pattern to the .godocignore's directory,
+		// otherwise it matches a name at any depth
+		prefix, glob := "^(.*/)?", line
+		if strings.Contains(line, "/") {
+			prefix, glob = "^", strings.TrimPrefix(line, "/")
+		}
+		expr, err := globToRegexp(glob)
+		if err == nil {
+			rule.pattern, err = regexp.Compile(prefix + expr + "$")
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, models.Errorf(models.CodeSettings, "%s pattern '%s' is malformed: %v", ignoreFile, line, err).WithFix("check the pattern's [...] classes, or escape a literal '[' as '\\['").At(pos))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, diagnostics
+}
+
+// globToRegexp translates a gitignore glob into a regular expression. '*' and '?' stay within a path segment,
+// while '**/' matches any number of directories and a trailing '/**' everything inside a directory.
+func globToRegexp(glob string) (string, error) {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		atSegmentStart := i == 0 || glob[i-1] == '/'
+		switch {
+		case atSegmentStart && strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case atSegmentStart && glob[i:] == "**":
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		case glob[i] == '?':
+			expr.WriteString("[^/]")
+		case glob[i] == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case glob[i] == '[':
+			class, width, err := classToRegexp(glob[i:])
+			if err != nil {
+				return "", err
+			}
+			expr.WriteString(class)
+			i += width - 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return expr.String(), nil
+}
+
+// classToRegexp translates the bracket expression glob starts with, such as '[a-z]', '[!._]' or '[[:digit:]]',
+// and returns it along with how many bytes of glob it took up. A ']' right after the '[' or '[!' is part of the
+// class, as in .gitignore, and a class never matches the '/' between path segments.
+func classToRegexp(glob string) (string, int, error) {
+	var class strings.Builder
+	class.WriteString("[")
+	i := 1
+	if i < len(glob) && (glob[i] == '!' || glob[i] == '^') {
+		class.WriteString("^/")
+		i++
+	}
+	for first := true; i < len(glob); first = false {
+		switch c := glob[i]; {
+		case c == ']' && !first:
+			return class.String() + "]", i + 1, nil
+		case c == '[' && strings.HasPrefix(glob[i:], "[:"):
+			end := strings.Index(glob[i+2:], ":]")
+			if end < 0 {
+				return "", 0, fmt.Errorf("'[:' has no closing ':]'")
+			}
+			// Go's regexp takes the same [:name:] forms, and rejects names it doesn't know
+			class.WriteString(glob[i : i+2+end+2])
+			i += 2 + end + 2
+		case c == '\\' && i+1 < len(glob):
+			class.WriteString(classChar(glob[i+1]))
+			i += 2
+		default:
+			class.WriteString(classChar(c))
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("'[' has no closing ']'")
+}
+
+// classChar writes a character so it means itself inside a regexp bracket expression. '-' is left as it is, since it
+// makes ranges in globs too.
+func classChar(c byte) string {
+	if strings.IndexByte(`\[]^`, c) >= 0 {
+		return `\` + string(c)
+	}
+	return string(c)
+}
+
+// readIgnoreFile returns the lines of a directory's .godocignore, or nothing if it doesn't have one
+func readIgnoreFile(dir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(dir, ignoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// walkProject walks a root directory like filepath.WalkDir, but only visits the files .godocignore leaves in.
+// Ignored directories aren't entered at all. included is true when a '!' pattern explicitly brought the file back.
+func (p *Parser) walkProject(root string, visit func(path string, included bool)) error {
+	rules, _ := ignoreRules(nil).add("", "", defaultIgnores)
+	return filepath.WalkDir(root, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel != "." {
+			if ignored, _ := rules.match(rel, entry.IsDir()); ignored && entry.IsDir() {
+				return filepath.SkipDir
+			} else if ignored {
+				return nil
+			}
+		}
+		if !entry.IsDir() {
+			_, matched := rules.match(rel, false)
+			visit(filePath, matched)
+			return nil
+		}
+
+		// Rules in this directory's .godocignore apply to everything below it
+		ignorePath := path.Join(filepath.ToSlash(filePath), ignoreFile)
+		lines, err := readIgnoreFile(filePath)
+		if rel == "." {
+			rel = ""
+		}
+		var diagnostics []models.Diagnostic
+		rules, diagnostics = rules.add(rel, ignorePath, lines)
+		if !p.ignoreFiles[ignorePath] {
+			p.ignoreFiles[ignorePath] = true
+			if err != nil {
+				p.report(models.Errorf(models.CodeIO, "error reading %s: %v", ignoreFile, err), models.Position{File: ignorePath})
+			}
+			p.Diagnostics = append(p.Diagnostics, diagnostics...)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,479 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// parseInterface reads an INTERFACE block. Each '@method' starts a method, and the '@param' and '@return' tags
+// after it belong to that method.
+func (p *Parser) parseInterface(comment models.Comment, comments []models.Comment) {
+	var iface models.Interface
+	iface.Pos = comment.Pos
+	tags, err := extractCommentTags(comment)
+	if err != nil {
+		p.report(err, comment.Pos)
+	} else {
+		var method *models.Func // Method the tags are currently describing
+		for _, tag := range tags {
+			if tag.Name == "interface" || tag.Name == "iface" || tag.Name == "name" {
+				iface.Name = tag.Content
+			} else if tag.Name == "description" || tag.Name == "desc" || tag.Name == "brief" {
+				iface.Desc = tag.Content
+			} else if tag.Name == "method" || tag.Name == "m" {
+				function, err := extractMethodContent(tag.Content)
+				if err != nil {
+					p.report(err, tag.Pos)
+					method = nil
+					continue
+				}
+				function.Pos = tag.Pos
+				iface.Methods = append(iface.Methods, function)
+				method = &iface.Methods[len(iface.Methods)-1]
+			} else if tag.Name == "parameter" || tag.Name == "param" || tag.Name == "p" ||
+				tag.Name == "return" || tag.Name == "returns" || tag.Name == "ret" {
+				if method == nil {
+					p.report(models.Errorf(models.CodeMalformedTag, "'@%s' doesn't follow a '@method'", tag.Name).WithFix("describe parameters and return values after the '@method' they belong to"), tag.Pos)
+				} else if tag.Name == "parameter" || tag.Name == "param" || tag.Name == "p" {
+					param, err := extractVarContent(tag.Content, contentPosition(tag))
+					if err != nil {
+						p.report(err, tag.Pos)
+					} else {
+						param.Pos = tag.Pos
+						method.Params = append(method.Params, param)
+					}
+				} else if !strings.HasPrefix(tag.Content, "(") {
+					// The type may be left out and taken from the declaration
+					method.Returns = append(method.Returns, models.ReturnResponse{Desc: tag.Content})
+				} else {
+					ret, err := extractReturnContent(tag.Content, contentPosition(tag))
+					if err != nil {
+						p.report(err, tag.Pos)
+					} else {
+						method.Returns = append(method.Returns, ret)
+					}
+				}
+			} else {
+				p.report(models.Errorf(models.CodeUnknownTag, "tag name '%s' unrecognized for interface declaration", tag.Name).WithFix("use one of @interface, @description, @method, @param or @return"), tag.Pos)
+			}
+		}
+	}
+
+	p.resolveInterface(&iface, comment, comments)
+	if iface.Name == "" {
+		p.report(models.Errorf(models.CodeMissingName, "no name given for interface block and no declaration follows it").WithFix("add a '@interface name' tag or move the block directly above its declaration"), comment.Pos)
+		return
+	}
+	if unicode.IsUpper(rune(iface.Name[0])) {
+		// Exported, belongs to pkg
+		for i := range p.Packages {
+			if p.Packages[i].ImportPath == comment.ImportPath {
+				p.Packages[i].Interfaces = append(p.Packages[i].Interfaces, iface)
+			}
+		}
+	} else {
+		// Unexported, belongs to file
+		for i, pkg := range p.Packages {
+			for j := range pkg.Files {
+				if pkg.Files[j].Path == comment.File {
+					p.Packages[i].Files[j].Interfaces = append(p.Packages[i].Files[j].Interfaces, iface)
+				}
+			}
+		}
+	}
+}
+
+// extractMethodContent reads 'MyMethod: description' or 'MyMethod description'
+func extractMethodContent(content string) (models.Func, error) {
+	content = strings.TrimSpace(content)
+	end := strings.IndexAny(content, ": \t\n")
+	if end == -1 {
+		end = len(content)
+	}
+	name := content[:end]
+	if !token.IsIdentifier(name) {
+		return models.Func{}, models.Errorf(models.CodeMalformedTag, "expected format: 'MyMethod: Description of MyMethod'")
+	}
+	desc := strings.TrimPrefix(strings.TrimSpace(content[end:]), ":")
+	return models.Func{Name: name, Desc: strings.TrimSpace(desc)}, nil
+}
+
+// resolveInterface checks an INTERFACE block against its declaration. Documented methods take their signatures
+// from the declaration, and declared methods the block leaves out are added.
+func (p *Parser) resolveInterface(iface *models.Interface, comment models.Comment, comments []models.Comment) {
+	where := comment.Pos
+	files := p.packageFiles(comment.File)
+	if len(files) == 0 {
+		return
+	}
+
+	spec, _ := p.attachedDecl(comment, comments).(*ast.TypeSpec)
+	if spec == nil || (iface.Name != "" && spec.Name.Name != iface.Name) {
+		if byName := findTypeSpec(files, iface.Name); byName != nil {
+			spec = byName
+		} else if spec != nil {
+			p.report(models.Warningf(models.CodeMismatch, "block for interface '%s' is followed by type '%s'", iface.Name, spec.Name.Name), where)
+			spec = nil
+		}
+	}
+	if spec == nil {
+		if iface.Name != "" {
+			p.report(models.Errorf(models.CodeNoDeclaration, "interface '%s' is documented but no matching declaration exists", iface.Name).WithFix("remove the block or correct the name it documents"), where)
+		}
+		return
+	}
+	iface.Name = spec.Name.Name
+
+	interfaceType, ok := spec.Type.(*ast.InterfaceType)
+	if !ok {
+		p.report(models.Warningf(models.CodeMismatch, "interface '%s' is declared as '%s'", iface.Name, types.ExprString(spec.Type)).WithFix("document it with a '-- TYPE' block instead"), where)
+		return
+	}
+
+	// Line the documented methods up with the declared ones, in declaration order
+	var methods []models.Func
+	used := make([]bool, len(iface.Methods))
+	for _, field := range interfaceType.Methods.List {
+		fnType, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			continue // Embedded interface or type constraint
+		}
+		name := field.Names[0].Name
+		method := models.Func{Name: name, Pos: p.declPosition(field.Pos())}
+		found := false
+		for i, documented := range iface.Methods {
+			if !used[i] && documented.Name == name {
+				used[i], found = true, true
+				method = documented
+				break
+			}
+		}
+		if !found && len(iface.Methods) > 0 {
+			p.report(models.Infof(models.CodeUndocumented, "method '%s' of interface '%s' is not documented", name, iface.Name).WithFix(fmt.Sprintf("add '@method %s: description'", name)), where)
+		}
+		p.resolveSignature(&method, fnType, method.Pos, fmt.Sprintf("method '%s.%s'", iface.Name, name))
+		methods = append(methods, method)
+	}
+	for i, documented := range iface.Methods {
+		if !used[i] {
+			p.report(models.Errorf(models.CodeNoDeclaration, "interface '%s' documents method '%s' which does not exist", iface.Name, documented.Name).WithFix("remove the tag"), documented.Pos)
+		}
+	}
+	iface.Methods = methods
+	iface.MethodSet = interfaceMethodSet(files, interfaceType, comment.ImportPath, typeParamNames(spec.TypeParams), map[string]bool{iface.Name: true})
+}
+
+// interfaceMethodSet lists the methods an interface requires, sorted by name. Interfaces it embeds are followed
+// within the package; if one can't be found (or the interface is a type constraint) the method set is unknown and nil is returned.
+// pkgPath is the import path of the package, and typeParams are the interface's type parameters, which are left unqualified.
+func interfaceMethodSet(files []*ast.File, interfaceType *ast.InterfaceType, pkgPath string, typeParams []string, seen map[string]bool) []models.Method {
+	q := newQualifier(fileOf(files, interfaceType), pkgPath, typeParams)
+	var methods []models.Method
+	for _, field := range interfaceType.Methods.List {
+		if fnType, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+			methods = append(methods, models.Method{Name: field.Names[0].Name, Signature: q.signature(fnType)})
+			continue
+		}
+
+		ident, ok := field.Type.(*ast.Ident)
+		if !ok || seen[ident.Name] {
+			return nil
+		}
+		spec := findTypeSpec(files, ident.Name)
+		if spec == nil {
+			return nil
+		}
+		embedded, ok := spec.Type.(*ast.InterfaceType)
+		if !ok {
+			return nil
+		}
+		seen[ident.Name] = true
+		embeddedMethods := interfaceMethodSet(files, embedded, pkgPath, typeParamNames(spec.TypeParams), seen)
+		if embeddedMethods == nil {
+			return nil
+		}
+		methods = append(methods, embeddedMethods...)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return methods
+}
+
+// typeMethodSet lists the methods declared on a named type in the package's files, sorted by name.
+// pkgPath is the import path of the package.
+func typeMethodSet(files []*ast.File, typeName, pkgPath string) []models.Method {
+	var methods []models.Method
+	pkgName := files[0].Name.Name
+	for _, file := range files {
+		if file.Name.Name != pkgName {
+			continue // External test package
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || receiverName(fn) != typeName {
+				continue
+			}
+			_, pointer := fn.Recv.List[0].Type.(*ast.StarExpr)
+			q := newQualifier(file, pkgPath, receiverTypeParams(fn))
+			methods = append(methods, models.Method{Name: fn.Name.Name, Signature: q.signature(fn.Type), Pointer: pointer})
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+	return methods
+}
+
+// fileOf returns the file a node was parsed from
+func fileOf(files []*ast.File, node ast.Node) *ast.File {
+	for _, file := range files {
+		if file.FileStart <= node.Pos() && node.Pos() < file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// qualifier writes the type expressions of one file with every named type qualified by the import path of the
+// package declaring it. 'User' declared in example.com/app/types and 'types.User' imported from it both read
+// 'example.com/app/types.User', while a 'types.User' imported from another package named types doesn't.
+type qualifier struct {
+	pkgPath    string            // Import path of the file's package
+	imports    map[string]string // Name each import is referred to by in the file -> import path
+	typeParams []string          // Type parameters in scope, which aren't declared in any package and are left as they are
+}
+
+func newQualifier(file *ast.File, pkgPath string, typeParams []string) qualifier {
+	q := qualifier{pkgPath: pkgPath, imports: make(map[string]string), typeParams: typeParams}
+	if file == nil {
+		return q
+	}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		q.imports[name] = importPath
+	}
+	return q
+}
+
+// importName guesses the name a package is referred to by from its import path, since the package itself isn't read:
+// the last element, skipping a major version suffix, as in 'github.com/go-chi/chi/v5'
+func importName(importPath string) string {
+	elements := strings.Split(importPath, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elements[len(elements)-2]
+	}
+	return name
+}
+
+// signature writes a func type's parameter and result types, e.g. '(int, ...string) (example.com/app/model.User, error)'.
+// The func type's own type parameters are added to those already in scope.
+func (q qualifier) signature(fnType *ast.FuncType) string {
+	q.typeParams = append(typeParamNames(fnType.TypeParams), q.typeParams...)
+	list := func(fields *ast.FieldList) []string {
+		var typeNames []string
+		if fields == nil {
+			return typeNames
+		}
+		for _, field := range fields.List {
+			typeName := q.qualifiedType(field.Type)
+			for i := 0; i < len(field.Names) || (i == 0 && len(field.Names) == 0); i++ {
+				typeNames = append(typeNames, typeName)
+			}
+		}
+		return typeNames
+	}
+
+	sig := "(" + strings.Join(list(fnType.Params), ", ") + ")"
+	results := list(fnType.Results)
+	if len(results) == 1 {
+		sig += " " + results[0]
+	} else if len(results) > 1 {
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
+
+// qualifiedType writes a type expression with named types qualified by the import path of their package
+func (q qualifier) qualifiedType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(e.Name) != nil || slices.Contains(q.typeParams, e.Name) {
+			return e.Name
+		}
+		return q.pkgPath + "." + e.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok {
+			if importPath, ok := q.imports[pkg.Name]; ok {
+				return importPath + "." + e.Sel.Name
+			}
+		}
+	case *ast.StarExpr:
+		return "*" + q.qualifiedType(e.X)
+	case *ast.ParenExpr:
+		return "(" + q.qualifiedType(e.X) + ")"
+	case *ast.Ellipsis:
+		return "..." + q.qualifiedType(e.Elt)
+	case *ast.ArrayType:
+		length := ""
+		if e.Len != nil {
+			length = types.ExprString(e.Len)
+		}
+		return "[" + length + "]" + q.qualifiedType(e.Elt)
+	case *ast.MapType:
+		return "map[" + q.qualifiedType(e.Key) + "]" + q.qualifiedType(e.Value)
+	case *ast.ChanType:
+		switch e.Dir {
+		case ast.SEND:
+			return "chan<- " + q.qualifiedType(e.Value)
+		case ast.RECV:
+			return "<-chan " + q.qualifiedType(e.Value)
+		}
+		return "chan " + q.qualifiedType(e.Value)
+	case *ast.FuncType:
+		return "func" + q.signature(e)
+	case *ast.IndexExpr:
+		return q.qualifiedType(e.X) + "[" + q.qualifiedType(e.Index) + "]"
+	case *ast.IndexListExpr:
+		var indices []string
+		for _, index := range e.Indices {
+			indices = append(indices, q.qualifiedType(index))
+		}
+		return q.qualifiedType(e.X) + "[" + strings.Join(indices, ", ") + "]"
+	}
+	return types.ExprString(expr)
+}
+
+// findImplementers lists, for every documented interface, the documented types in the project that implement it.
+// Types are matched by their method names and signatures.
+func (p *Parser) findImplementers() {
+	type namedType struct {
+		pkg   *models.Package
+		_type models.Type
+	}
+	var documented []namedType
+	for i := range p.Packages {
+		pkg := &p.Packages[i]
+		for _, _type := range pkg.Types {
+			documented = append(documented, namedType{pkg, _type})
+		}
+		for _, file := range pkg.Files {
+			for _, _type := range file.Types {
+				documented = append(documented, namedType{pkg, _type})
+			}
+		}
+	}
+
+	implementers := func(pkg *models.Package, iface models.Interface) []string {
+		var names []string
+		for _, candidate := range documented {
+			samePackage := candidate.pkg.ImportPath == pkg.ImportPath
+			name, ok := implements(candidate._type, iface, samePackage)
+			if !ok {
+				continue
+			}
+			if !samePackage {
+				pointer := strings.HasPrefix(name, "*")
+				name = candidate.pkg.Name + "." + strings.TrimPrefix(name, "*")
+				if pointer {
+					name = "*" + name
+				}
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+
+	for i := range p.Packages {
+		pkg := &p.Packages[i]
+		for j := range pkg.Interfaces {
+			pkg.Interfaces[j].Implementers = implementers(pkg, pkg.Interfaces[j])
+		}
+		for j := range pkg.Files {
+			for k := range pkg.Files[j].Interfaces {
+				pkg.Files[j].Interfaces[k].Implementers = implementers(pkg, pkg.Files[j].Interfaces[k])
+			}
+		}
+	}
+}
+
+// implements reports whether a type has every method of an interface, returning 'T' if the type's value does
+// or '*T' if only a pointer to it does
+func implements(_type models.Type, iface models.Interface, samePackage bool) (string, bool) {
+	if len(iface.MethodSet) == 0 {
+		return "", false // Every type implements an empty interface, which isn't worth listing
+	}
+	needsPointer := false
+	for _, required := range iface.MethodSet {
+		if !samePackage && !token.IsExported(required.Name) {
+			return "", false
+		}
+		found := false
+		for _, method := range _type.MethodSet {
+			if method.Name == required.Name && method.Signature == required.Signature {
+				found = true
+				needsPointer = needsPointer || method.Pointer
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	if needsPointer {
+		return "*" + _type.Name, true
+	}
+	return _type.Name, true
+}
+
+// typeParamNames lists the names a type parameter list declares, such as 'K' and 'V' in '[K comparable, V any]'
+func typeParamNames(fields *ast.FieldList) []string {
+	var names []string
+	if fields == nil {
+		return names
+	}
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// receiverTypeParams lists the type parameters a method's receiver names, such as 'T' in 'func (l *List[T]) Push(v T)'
+func receiverTypeParams(fn *ast.FuncDecl) []string {
+	var names []string
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return names
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	var indices []ast.Expr
+	switch e := recv.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{e.Index}
+	case *ast.IndexListExpr:
+		indices = e.Indices
+	}
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			names = append(names, ident.Name)
+		}
+	}
+	return names
+}
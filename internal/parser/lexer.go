@@ -0,0 +1,71 @@
+package parser
+
+// lexer walks through a string one byte at a time.
+// Every file and every tag gets its own lexer, so nothing is shared between files being read at the same time.
+type lexer struct {
+	src          string
+	position     int  // Offset of ch
+	readPosition int  // Offset of the next byte
+	ch           byte // Current byte, 0 at the end of input
+}
+
+func newLexer(src string) *lexer {
+	l := &lexer{src: src}
+	l.readChar()
+	return l
+}
+
+func (l *lexer) readChar() {
+	if l.readPosition >= len(l.src) { // Check if the end of input is reached
+		l.ch = 0 // Null character indicating end of input
+	} else {
+		l.ch = l.src[l.readPosition] // Read the current character
+	}
+	l.position = l.readPosition // Update the current position
+	l.readPosition += 1         // Move to the next character
+}
+
+func (l *lexer) peekChar(ahead int) byte {
+	if l.readPosition+ahead >= len(l.src) {
+		return 0
+	}
+	return l.src[l.readPosition+ahead]
+}
+
+func (l *lexer) advanceBy(n int) {
+	for i := 0; i < n; i++ {
+		l.readChar()
+	}
+}
+
+func (l *lexer) skipWhitespace() {
+	for isWhitespace(l.ch) {
+		l.readChar()
+	}
+}
+
+func (l *lexer) atLineStart() bool {
+	return l.position == 0 || l.src[l.position-1] == '\n'
+}
+
+// afterWhitespace reports whether ch starts the text or follows whitespace
+func (l *lexer) afterWhitespace() bool {
+	return l.position == 0 || isWhitespace(l.src[l.position-1])
+}
+
+func isWhitespace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+func (l *lexer) isGoDocComment() bool {
+	return l.ch == '/' && l.peekChar(0) == '*' && l.peekChar(1) == '*' && l.peekChar(2) == '*'
+}
+
+// isDoxygenComment reports whether ch starts a /** comment, which may be written in the Doxygen/Javadoc dialect
+func (l *lexer) isDoxygenComment() bool {
+	return l.ch == '/' && l.peekChar(0) == '*' && l.peekChar(1) == '*' && l.peekChar(2) != '*' && l.peekChar(2) != '/'
+}
+
+func (l *lexer) isAtEnd() bool {
+	return l.readPosition >= len(l.src)
+}
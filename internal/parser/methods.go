@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"go/ast"
+	"path/filepath"
+	"sort"
+	"unicode"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// groupMethods moves every method under the type it is declared on, and every constructor (a func returning exactly
+// one of the package's types, optionally with an error) under the type it returns, the way pkg.go.dev lays packages out.
+// A receiver type without a block of its own is added to the package so its methods have somewhere to go.
+func (p *Parser) groupMethods(pkg *models.Package) {
+	// Methods first, since they may add types that constructors then belong to
+	var funcs []models.Func
+	for _, function := range pkg.Funcs {
+		if function.Receiver == "" {
+			funcs = append(funcs, function)
+			continue
+		}
+		_type := p.receiverType(pkg, trimPointer(function.Receiver), "")
+		_type.Methods = append(_type.Methods, function)
+	}
+	for i := range pkg.Files {
+		var fileFuncs []models.Func
+		for _, function := range pkg.Files[i].Funcs {
+			if function.Receiver == "" {
+				fileFuncs = append(fileFuncs, function)
+				continue
+			}
+			_type := p.receiverType(pkg, trimPointer(function.Receiver), pkg.Files[i].Path)
+			_type.Methods = append(_type.Methods, function)
+		}
+		pkg.Files[i].Funcs = fileFuncs
+	}
+	pkg.Funcs = funcs
+
+	funcs = nil
+	for _, function := range pkg.Funcs {
+		if _type := constructedType(pkg, function); _type != nil {
+			_type.Constructors = append(_type.Constructors, function)
+		} else {
+			funcs = append(funcs, function)
+		}
+	}
+	pkg.Funcs = funcs
+	for i := range pkg.Files {
+		var fileFuncs []models.Func
+		for _, function := range pkg.Files[i].Funcs {
+			if _type := constructedType(pkg, function); _type != nil {
+				_type.Constructors = append(_type.Constructors, function)
+			} else {
+				fileFuncs = append(fileFuncs, function)
+			}
+		}
+		pkg.Files[i].Funcs = fileFuncs
+	}
+}
+
+// receiverType finds a method's receiver type in the package, adding it if the type has no block.
+// Exported types are added to the package, unexported ones to the file the method is documented in.
+func (p *Parser) receiverType(pkg *models.Package, name, filePath string) *models.Type {
+	if _type := findType(pkg, name); _type != nil {
+		return _type
+	}
+
+	_type := models.Type{Name: name}
+	if files := p.dirFiles(pkg.Dir, pkg.Name); len(files) > 0 {
+		if spec := findTypeSpec(files, name); spec != nil {
+			_type.Pos = p.declPosition(spec.Pos())
+		}
+		_type.MethodSet = typeMethodSet(files, name, pkg.ImportPath)
+	}
+
+	if unicode.IsUpper(rune(name[0])) || filePath == "" {
+		pkg.Types = append(pkg.Types, _type)
+		return &pkg.Types[len(pkg.Types)-1]
+	}
+	for i := range pkg.Files {
+		if pkg.Files[i].Path == filePath {
+			pkg.Files[i].Types = append(pkg.Files[i].Types, _type)
+			return &pkg.Files[i].Types[len(pkg.Files[i].Types)-1]
+		}
+	}
+	pkg.Types = append(pkg.Types, _type)
+	return &pkg.Types[len(pkg.Types)-1]
+}
+
+// constructedType returns the type a func constructs: the only type of the package among its results,
+// which may also include an error
+func constructedType(pkg *models.Package, function models.Func) *models.Type {
+	if function.Receiver != "" {
+		return nil
+	}
+	var found *models.Type
+	for _, ret := range function.Returns {
+		name := trimPointer(ret.Paren)
+		if name == "error" {
+			continue
+		}
+		_type := findType(pkg, name)
+		if _type == nil || (found != nil && found != _type) {
+			return nil
+		}
+		found = _type
+	}
+	return found
+}
+
+// findType looks a type up by name among the package's types and its files' types
+func findType(pkg *models.Package, name string) *models.Type {
+	for i := range pkg.Types {
+		if pkg.Types[i].Name == name {
+			return &pkg.Types[i]
+		}
+	}
+	for i := range pkg.Files {
+		for j := range pkg.Files[i].Types {
+			if pkg.Files[i].Types[j].Name == name {
+				return &pkg.Files[i].Types[j]
+			}
+		}
+	}
+	return nil
+}
+
+// dirFiles returns the parsed files of a package in a directory, sorted by path. An empty pkgName matches every package.
+func (p *Parser) dirFiles(dir, pkgName string) []*ast.File {
+	var paths []string
+	for path, file := range p.files {
+		if filepath.Dir(path) == dir && (pkgName == "" || file.Name.Name == pkgName) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var files []*ast.File
+	for _, path := range paths {
+		files = append(files, p.files[path])
+	}
+	return files
+}
@@ -0,0 +1,276 @@
+package parser
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// discoverModules finds every module the project spans: the module ProjectPath sits in, every go.mod below it,
+// and every module listed by a go.work that covers it. The returned directories outside ProjectPath must be walked too.
+func (p *Parser) discoverModules() []string {
+	var extraRoots []string
+
+	projectAbs, err := filepath.Abs(p.settings.ProjectPath)
+	if err != nil {
+		p.report(models.Errorf(models.CodeIO, "error resolving project path: %v", err), models.Position{File: p.settings.ProjectPath})
+		return extraRoots
+	}
+
+	// The module that owns ProjectPath itself, which may be declared above it
+	if modDir, found := findModuleDir(projectAbs); found {
+		p.addModule(relativeTo(projectAbs, p.settings.ProjectPath, modDir))
+	}
+
+	// Nested modules
+	err = p.walkProject(p.settings.ProjectPath, func(path string, _ bool) {
+		if filepath.Base(path) == "go.mod" {
+			p.addModule(filepath.Dir(path))
+		}
+	})
+	if err != nil {
+		p.report(models.Errorf(models.CodeIO, "error looking for modules in project directory: %v", err), models.Position{File: p.settings.ProjectPath})
+	}
+
+	// Workspace modules
+	if workFile, found := findGoWork(projectAbs); found {
+		uses, err := readGoWork(workFile)
+		if err != nil {
+			p.report(models.Errorf(models.CodeModule, "error reading workspace file: %v", err), models.Position{File: workFile})
+		}
+		for _, use := range uses {
+			useAbs := filepath.Join(filepath.Dir(workFile), filepath.FromSlash(use))
+			useDir := relativeTo(projectAbs, p.settings.ProjectPath, useAbs)
+			if p.addModule(useDir) && !isWithin(projectAbs, useAbs) {
+				extraRoots = append(extraRoots, useDir)
+			}
+		}
+	}
+
+	return extraRoots
+}
+
+// addModule records the module rooted at dir, returning false if it is unreadable or already known
+func (p *Parser) addModule(dir string) bool {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, mod := range p.modules {
+		if existing, _ := filepath.Abs(mod.Dir); existing == abs {
+			return false
+		}
+	}
+
+	modPath, goVersion, err := readGoMod(filepath.Join(dir, "go.mod"))
+	if err != nil || modPath == "" {
+		p.report(models.Errorf(models.CodeModule, "expected a go.mod with a module directive").WithFix("add a 'module' line to go.mod"), models.Position{File: filepath.Join(dir, "go.mod")})
+		return false
+	}
+	p.modules = append(p.modules, models.Module{Path: modPath, Dir: dir, GoVersion: goVersion})
+	return true
+}
+
+// moduleFor returns the innermost discovered module containing dir
+func (p *Parser) moduleFor(dir string) (models.Module, bool) {
+	var owner models.Module
+	found := false
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return owner, false
+	}
+	for _, mod := range p.modules {
+		modAbs, err := filepath.Abs(mod.Dir)
+		if err != nil || !isWithin(modAbs, abs) {
+			continue
+		}
+		if ownerAbs, _ := filepath.Abs(owner.Dir); !found || len(modAbs) > len(ownerAbs) {
+			owner = mod
+			found = true
+		}
+	}
+	return owner, found
+}
+
+// importPathFor works out the import path of a directory from the module it belongs to.
+// Without a module, the directory's path relative to the project is used instead.
+func (p *Parser) importPathFor(dir string) string {
+	if importPath, ok := p.importPaths[dir]; ok {
+		return importPath
+	}
+
+	importPath := ""
+	if mod, found := p.moduleFor(dir); found {
+		modAbs, _ := filepath.Abs(mod.Dir)
+		abs, _ := filepath.Abs(dir)
+		if rel, err := filepath.Rel(modAbs, abs); err == nil {
+			importPath = path.Join(mod.Path, filepath.ToSlash(rel))
+		}
+	}
+	if importPath == "" {
+		rel, err := filepath.Rel(p.settings.ProjectPath, dir)
+		if err != nil {
+			rel = dir
+		}
+		importPath = filepath.ToSlash(rel)
+	}
+
+	p.importPaths[dir] = importPath
+	return importPath
+}
+
+// groupModules places every package under its owning module, in discovery order.
+// Packages outside any module are collected under a module with no path.
+func (p *Parser) groupModules() {
+	modules := make([]models.Module, len(p.modules))
+	copy(modules, p.modules)
+	var orphans models.Module
+
+	for _, pkg := range p.Packages {
+		if mod, found := p.moduleFor(pkg.Dir); found {
+			for i := range modules {
+				if modules[i].Dir == mod.Dir {
+					modules[i].Packages = append(modules[i].Packages, pkg)
+				}
+			}
+		} else {
+			orphans.Packages = append(orphans.Packages, pkg)
+		}
+	}
+
+	p.Modules = nil
+	for _, mod := range modules {
+		if len(mod.Packages) > 0 {
+			sortPackages(mod.Packages)
+			p.Modules = append(p.Modules, mod)
+		}
+	}
+	if len(orphans.Packages) > 0 {
+		orphans.Dir = p.settings.ProjectPath
+		sortPackages(orphans.Packages)
+		p.Modules = append(p.Modules, orphans)
+	}
+}
+
+func sortPackages(packages []models.Package) {
+	sort.SliceStable(packages, func(i, j int) bool {
+		return packages[i].ImportPath < packages[j].ImportPath
+	})
+}
+
+// findModuleDir walks up from an absolute dir to the nearest directory holding a go.mod
+func findModuleDir(dir string) (string, bool) {
+	return findUp(dir, "go.mod")
+}
+
+// findGoWork walks up from an absolute dir to the nearest go.work, the same way the go command does
+func findGoWork(dir string) (string, bool) {
+	workDir, found := findUp(dir, "go.work")
+	if !found {
+		return "", false
+	}
+	return filepath.Join(workDir, "go.work"), true
+}
+
+func findUp(dir, name string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// readGoMod reads the module path and go version out of a go.mod file
+func readGoMod(goModPath string) (string, string, error) {
+	modPath, goVersion := "", ""
+	err := readModFileLines(goModPath, func(directive string, args []string) {
+		if len(args) == 0 {
+			return
+		}
+		switch directive {
+		case "module":
+			modPath = args[0]
+		case "go":
+			goVersion = args[0]
+		}
+	})
+	return modPath, goVersion, err
+}
+
+// readGoWork reads the directories listed by use directives in a go.work file
+func readGoWork(goWorkPath string) ([]string, error) {
+	var uses []string
+	err := readModFileLines(goWorkPath, func(directive string, args []string) {
+		if directive == "use" && len(args) > 0 {
+			uses = append(uses, args[0])
+		}
+	})
+	return uses, err
+}
+
+// readModFileLines walks the directives of a go.mod/go.work file, expanding `directive ( ... )` blocks
+func readModFileLines(filePath string, visit func(directive string, args []string)) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	block := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		for i := range fields {
+			fields[i] = strings.Trim(fields[i], "\"`")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+			} else {
+				visit(block, fields)
+			}
+			continue
+		}
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		visit(fields[0], fields[1:])
+	}
+	return scanner.Err()
+}
+
+// relativeTo expresses an absolute path in the same form as the project path, so relative settings stay relative
+func relativeTo(projectAbs, projectPath, target string) string {
+	if filepath.IsAbs(projectPath) {
+		return target
+	}
+	rel, err := filepath.Rel(projectAbs, target)
+	if err != nil {
+		return target
+	}
+	return filepath.Join(projectPath, rel)
+}
+
+func isWithin(parent, child string) bool {
+	rel, err := filepath.Rel(parent, child)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -1,9 +1,11 @@
 package parser
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
 	"log"
 	"os"
 	"path/filepath"
@@ -14,88 +16,159 @@ import (
 )
 
 type Parser struct {
-	settings     models.Settings
-	src          string
-	position     int
-	readPosition int
-	ch           byte
-	Packages     []models.Package
-	Errors       []error
+	settings    models.Settings
+	fset        *token.FileSet // Safe to share between the workers reading files
+	files       map[string]*ast.File
+	importPaths map[string]string      // Directory -> import path
+	modules     []models.Module        // Discovered modules, without their packages
+	typeInfo    map[string]*types.Info // Directory -> type-checked package, for evaluating constants
+	builds      map[string]string      // File -> build constraint, for files not built on every platform
+	ignoreFiles map[string]bool        // .godocignore files already read, so walking the project again doesn't report them twice
+	Packages    []models.Package
+	Modules     []models.Module // Packages grouped by module, as written to the JSON output
+	Diagnostics []models.Diagnostic
+	Force       bool // Ignore the cache and read every file
+	cached      int  // Files taken from the cache by the last save
 }
 
 func New(settings models.Settings) *Parser {
-	return &Parser{settings: settings}
+	return &Parser{
+		settings:    settings,
+		fset:        token.NewFileSet(),
+		files:       make(map[string]*ast.File),
+		importPaths: make(map[string]string),
+		typeInfo:    make(map[string]*types.Info),
+		builds:      make(map[string]string),
+		ignoreFiles: make(map[string]bool),
+	}
 }
 
 func (p *Parser) ParseProject() {
-	var comments []models.Comment
-	// Walk through all the files in the directory
-	err := filepath.WalkDir(p.settings.ProjectPath, func(path string, entry os.DirEntry, err error) error {
-		if err != nil {
-			return err
+	// Find where modules start and end before any import paths are worked out
+	roots := append([]string{p.settings.ProjectPath}, p.discoverModules()...)
+	p.checkExcludePackages()
+
+	// Read and hash every file on a pool of workers
+	files := p.collectSourceFiles(roots)
+	forEachFile(files, loadSourceFile)
+	files = dropGenerated(files)
+	files = p.dropOtherPlatforms(files)
+
+	// Directories that haven't changed since the last save are taken straight from the cache
+	key := p.cacheKey()
+	previous := p.loadCache(key)
+	next := newCache(key)
+	dirs := groupByDir(files)
+	var changed []*sourceFile
+	for _, dir := range dirs {
+		dir.unchanged = previous.unchanged(dir)
+		if !dir.unchanged {
+			changed = append(changed, dir.files...)
 		}
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
-			if p.settings.IncludeTests || (!p.settings.IncludeTests && !strings.HasSuffix(entry.Name(), "_test.go")) {
-				fileComments := p.parseSourceCode(path)
-				if len(fileComments) > 0 {
-					log.Printf("%d comments found in %s\n", len(fileComments), path)
-					comments = append(comments, fileComments...)
-				}
-			}
-		}
-		return nil
+	}
+	p.cached = len(files) - len(changed)
+	if p.cached > 0 {
+		log.Printf("%d unchanged files taken from the cache\n", p.cached)
+	}
+
+	// Parse the changed files on the pool too
+	forEachFile(changed, func(f *sourceFile) {
+		cached, hit := previous.cachedFile(f)
+		p.readSourceFile(f, cached, hit)
 	})
 
-	if err != nil {
-		p.Errors = append(p.Errors, fmt.Errorf("error walking through project directory: %v", err))
+	// Put each directory's package together in walk order, so the output never depends on timing or on what was cached
+	for _, dir := range dirs {
+		if !dir.unchanged {
+			next.Dirs[dir.path] = p.assembleDir(dir, next)
+			continue
+		}
+		for _, file := range dir.files {
+			cached := previous.Files[file.path]
+			p.Diagnostics = append(p.Diagnostics, cached.Diagnostics...)
+			next.Files[file.path] = cached
+		}
+		cached := previous.Dirs[dir.path]
+		p.Packages = append(p.Packages, cached.Packages...)
+		p.Diagnostics = append(p.Diagnostics, cached.Diagnostics...)
+		next.Dirs[dir.path] = cached
 	}
 
-	if len(comments) != 0 {
-		p.parseComments(comments)
+	p.findImplementers()
+	p.groupModules()
+	if err := p.writeToJson(); err != nil {
+		p.report(models.Errorf(models.CodeIO, "%v", err), models.Position{File: "./godoc_output.json"})
+	}
+	if err := next.save(); err != nil {
+		p.report(models.Warningf(models.CodeIO, "%v", err), models.Position{File: cacheFile})
 	}
-
-	p.writeToJson()
 }
 
-func (p *Parser) parseSourceCode(filePath string) []models.Comment {
+// assembleDir builds the package of a changed directory from its files' blocks, recording what it produced for the cache
+func (p *Parser) assembleDir(dir *sourceDir, next cache) cachedDir {
 	var comments []models.Comment
-
-	log.Printf("Reading file '%s'\n", filePath)
-
-	// Open the file
-	file, err := os.Open(filePath)
-	if err != nil {
-		p.Errors = append(p.Errors, err)
-		return comments
+	var paths []string
+	for _, file := range dir.files {
+		log.Printf("Reading file '%s'\n", file.path)
+		if file.ast != nil {
+			p.files[file.path] = file.ast
+		}
+		p.Diagnostics = append(p.Diagnostics, file.diagnostics...)
+		if len(file.comments) > 0 {
+			log.Printf("%d comments found in %s\n", len(file.comments), file.path)
+			comments = append(comments, file.comments...)
+		}
+		next.Files[file.path] = cachedFile{Hash: file.hash, Comments: file.comments, Diagnostics: file.diagnostics}
+		paths = append(paths, file.path)
 	}
-	defer file.Close()
 
-	// Read the content of the file into e.src
-	content, err := p.readFile(file)
-	if err != nil {
-		p.Errors = append(p.Errors, err)
-		return comments
+	firstPackage, firstDiagnostic := len(p.Packages), len(p.Diagnostics)
+	if len(comments) != 0 {
+		p.parseComments(comments)
+	}
+	if p.settings.IncludeDocComments {
+		p.harvestDocComments(paths)
+	}
+	for i := firstPackage; i < len(p.Packages); i++ {
+		p.groupMethods(&p.Packages[i])
+		p.applyVisibility(&p.Packages[i])
 	}
-	p.src = content
-
-	pkgName := p.extractPkgName()
 
-	// Now proceed to extract comments
-	p.readPosition = 0
-	p.readChar() // Reset to the beginning of the file to start comment extraction
+	return cachedDir{
+		Files:       paths,
+		Packages:    append([]models.Package(nil), p.Packages[firstPackage:]...),
+		Diagnostics: append([]models.Diagnostic(nil), p.Diagnostics[firstDiagnostic:]...),
+	}
+}
 
-	for !p.isAtEnd() {
-		if p.isGoDocComment() {
-			comment := p.extractBlockComment(filePath, pkgName)
-			if !isEmptyComment(comment) {
-				comments = append(comments, comment)
+// collectSourceFiles walks through all the files in the project directory and any workspace modules outside it,
+// listing every .go file to read along with its import path
+func (p *Parser) collectSourceFiles(roots []string) []*sourceFile {
+	var files []*sourceFile
+	excluded := make(map[string]bool)
+	for _, root := range roots {
+		err := p.walkProject(root, func(path string, included bool) {
+			if !strings.HasSuffix(path, ".go") {
+				return
 			}
-		} else {
-			p.readChar()
+			importPath := p.importPathFor(filepath.Dir(path))
+			if p.isExcluded(importPath) {
+				if !excluded[importPath] {
+					log.Printf("Skipping excluded package '%s'\n", importPath)
+					excluded[importPath] = true
+				}
+				return
+			}
+			if p.settings.IncludeTests || (!p.settings.IncludeTests && !strings.HasSuffix(path, "_test.go")) {
+				files = append(files, &sourceFile{path: path, importPath: importPath, included: included})
+			}
+		})
+
+		if err != nil {
+			p.report(models.Errorf(models.CodeIO, "error walking through project directory: %v", err), models.Position{File: root})
 		}
 	}
-
-	return comments
+	return files
 }
 
 func (p *Parser) parseComments(comments []models.Comment) {
@@ -108,39 +181,46 @@ func (p *Parser) parseComments(comments []models.Comment) {
 	for _, comment := range comments {
 		// Get the header from the comment block
 		headerLine := comment.Text[0]
-		keyword, err := extractKeyword(headerLine, comment.File)
+		keyword, err := extractKeyword(headerLine)
 		if err != nil {
-			p.Errors = append(p.Errors, err)
+			// Already reported while initializing packages
+			continue
 		} else {
 			// Only want to evalute if there is a valid
-			text := strings.Join(comment.Text[1:], "\n") // All of the comment except the header line
 			// Retreive comment information according to block type
 			switch keyword {
 			case "TYPE", "T":
 				var _type models.Type
-				tags, err := p.extractTagData(text)
+				_type.Pos = comment.Pos
+				tags, err := extractCommentTags(comment)
 				if err != nil {
-					p.Errors = append(p.Errors, err)
+					p.report(err, comment.Pos)
 				} else {
 					for _, tag := range tags {
 						if tag.Name == "type" || tag.Name == "t" || tag.Name == "name" {
 							_type.Name = tag.Content
-						} else if tag.Name == "description" || tag.Name == "desc" {
+						} else if tag.Name == "description" || tag.Name == "desc" || tag.Name == "brief" {
 							_type.Desc = tag.Content
 						} else if tag.Name == "field" || tag.Name == "f" {
-							field, err := p.extractVarContent(tag.Content)
+							field, err := extractVarContent(tag.Content, contentPosition(tag))
 							if err != nil {
-								p.Errors = append(p.Errors, err)
+								p.report(err, tag.Pos)
 							} else {
+								field.Pos = tag.Pos
 								_type.Fields = append(_type.Fields, field)
 							}
 						}
 					}
 				}
+				p.resolveType(&_type, comment, comments)
+				if _type.Name == "" {
+					p.report(models.Errorf(models.CodeMissingName, "no name given for type block and no declaration follows it").WithFix("add a '@type name' tag or move the block directly above its declaration"), comment.Pos)
+					continue
+				}
 				if unicode.IsUpper(rune(_type.Name[0])) {
 					// Exported, belongs to pkg
 					for i := range p.Packages {
-						if p.Packages[i].Name == comment.Package {
+						if p.Packages[i].ImportPath == comment.ImportPath {
 							p.Packages[i].Types = append(p.Packages[i].Types, _type)
 						}
 					}
@@ -154,47 +234,63 @@ func (p *Parser) parseComments(comments []models.Comment) {
 						}
 					}
 				}
+			case "INTERFACE", "IFACE":
+				p.parseInterface(comment, comments)
+			case "CONST", "ENUM":
+				p.parseConstGroup(comment, comments)
 			case "FUNCTION", "FUNC":
 				var function models.Func
-				tags, err := p.extractTagData(text)
+				function.Pos = comment.Pos
+				tags, err := extractCommentTags(comment)
 				if err != nil {
-					p.Errors = append(p.Errors, err)
+					p.report(err, comment.Pos)
 				} else {
 					for _, tag := range tags {
 						if tag.Name == "function" || tag.Name == "func" || tag.Name == "f" || tag.Name == "name" {
 							function.Name = tag.Content
-						} else if tag.Name == "description" || tag.Name == "desc" {
+						} else if tag.Name == "description" || tag.Name == "desc" || tag.Name == "brief" {
 							function.Desc = tag.Content
 						} else if tag.Name == "receiver" || tag.Name == "rec" {
 							function.Receiver = tag.Content
 						} else if tag.Name == "parameter" || tag.Name == "param" || tag.Name == "p" {
-							param, err := p.extractVarContent(tag.Content)
+							param, err := extractVarContent(tag.Content, contentPosition(tag))
 							if err != nil {
-								p.Errors = append(p.Errors, err)
+								p.report(err, tag.Pos)
 							} else {
+								param.Pos = tag.Pos
 								function.Params = append(function.Params, param)
 							}
-						} else if tag.Name == "return" || tag.Name == "ret" {
-							ret, err := p.extractSpecialComment(tag.Content, comment.File)
+						} else if tag.Name == "return" || tag.Name == "returns" || tag.Name == "ret" {
+							// The type may be left out and taken from the declaration
+							if !strings.HasPrefix(tag.Content, "(") {
+								function.Returns = append(function.Returns, models.ReturnResponse{Desc: tag.Content})
+								continue
+							}
+							ret, err := extractReturnContent(tag.Content, contentPosition(tag))
 							if err != nil {
-								p.Errors = append(p.Errors, err)
+								p.report(err, tag.Pos)
 							} else {
 								function.Returns = append(function.Returns, ret)
 							}
 						} else if tag.Name == "response" || tag.Name == "res" {
-							res, err := p.extractSpecialComment(tag.Content, comment.File)
+							res, err := extractSpecialComment(tag.Content)
 							if err != nil {
-								p.Errors = append(p.Errors, err)
+								p.report(err, tag.Pos)
 							} else {
 								function.Responses = append(function.Responses, res)
 							}
 						}
 					}
 				}
+				p.resolveFunc(&function, comment, comments)
+				if function.Name == "" {
+					p.report(models.Errorf(models.CodeMissingName, "no name given for func block and no declaration follows it").WithFix("add a '@func name' tag or move the block directly above its declaration"), comment.Pos)
+					continue
+				}
 				if unicode.IsUpper(rune(function.Name[0])) {
 					// Exported, belongs to pkg
 					for i := range p.Packages {
-						if p.Packages[i].Name == comment.Package {
+						if p.Packages[i].ImportPath == comment.ImportPath {
 							p.Packages[i].Funcs = append(p.Packages[i].Funcs, function)
 						}
 					}
@@ -210,24 +306,30 @@ func (p *Parser) parseComments(comments []models.Comment) {
 				}
 			case "VARIABLE", "VAR", "V":
 				var variable models.Var
-				tags, err := p.extractTagData(text)
+				variable.Pos = comment.Pos
+				tags, err := extractCommentTags(comment)
 				if err != nil {
-					p.Errors = append(p.Errors, err)
+					p.report(err, comment.Pos)
 				} else {
 					for _, tag := range tags {
 						if tag.Name == "variable" || tag.Name == "var" || tag.Name == "v" || tag.Name == "name" {
 							variable.Name = tag.Content
-						} else if tag.Name == "description" || tag.Name == "desc" {
+						} else if tag.Name == "description" || tag.Name == "desc" || tag.Name == "brief" {
 							variable.Desc = tag.Content
 						} else if tag.Name == "type" || tag.Name == "t" {
 							variable.Type = tag.Content
 						}
 					}
 				}
+				p.resolveVar(&variable, comment, comments)
+				if variable.Name == "" {
+					p.report(models.Errorf(models.CodeMissingName, "no name given for var block and no declaration follows it").WithFix("add a '@var name' tag or move the block directly above its declaration"), comment.Pos)
+					continue
+				}
 				if unicode.IsUpper(rune(variable.Name[0])) {
 					// Exported, belongs to pkg
 					for i := range p.Packages {
-						if p.Packages[i].Name == comment.Package {
+						if p.Packages[i].ImportPath == comment.ImportPath {
 							p.Packages[i].Vars = append(p.Packages[i].Vars, variable)
 						}
 					}
@@ -247,82 +349,75 @@ func (p *Parser) parseComments(comments []models.Comment) {
 }
 
 func (p *Parser) initializePackages(comments []models.Comment) {
-	var pkgNames []string // Prevents duplicate pkg declaration
-	// Get all pkg names
-	for _, comment := range comments {
-		if !contains(pkgNames, comment.Package) {
-			pkgNames = append(pkgNames, comment.Package)
-		}
-	}
-
 	for _, comment := range comments {
 		// Get the header from the comment block
 		headerLine := comment.Text[0]
-		keyword, err := extractKeyword(headerLine, comment.File)
+		keyword, err := extractKeyword(headerLine)
 		// fmt.Printf("%s\n", keyword)
 		if err != nil {
-			p.Errors = append(p.Errors, err)
+			p.report(err, comment.Pos)
 		} else {
 			// Only want to evalute if there is a valid
-			text := strings.Join(comment.Text[1:], "\n") // All of the comment except the header line
 			// Retreive comment information for pkg types. Also check for unrecognized headers here
 			// Most nodes cannot be assigned to a tree since all packages may not be accounted for yet
 			switch keyword {
 			case "PACKAGE", "PKG", "P":
 				var pkg models.Package
-				tags, err := p.extractTagData(text)
+				pkg.Pos = comment.Pos
+				tags, err := extractCommentTags(comment)
 				if err != nil {
-					p.Errors = append(p.Errors, err)
+					p.report(err, comment.Pos)
 				} else {
 					for _, tag := range tags {
 						// fmt.Printf("%s: %s\n", tag.Name, tag.Content)
 						if tag.Name == "package" || tag.Name == "pkg" || tag.Name == "p" || tag.Name == "name" {
 							pkg.Name = tag.Content
-						} else if tag.Name == "description" || tag.Name == "desc" {
+						} else if tag.Name == "description" || tag.Name == "desc" || tag.Name == "brief" {
 							pkg.Desc = tag.Content
 						} else if tag.Name == "usage" || tag.Name == "u" {
 							pkg.Usage = tag.Content
 						} else if tag.Name == "dependency" || tag.Name == "dep" {
-							dep, err := p.extractDependency(tag.Content)
+							dep, err := extractDependency(tag.Content)
 							if err != nil {
-								p.Errors = append(p.Errors, err)
+								p.report(err, tag.Pos)
 							} else {
 								pkg.Deps = append(pkg.Deps, dep)
 							}
 						} else {
-							p.Errors = append(p.Errors, fmt.Errorf("tag name '%s' unrecognized for pkg declaration", tag.Name))
+							p.report(models.Errorf(models.CodeUnknownTag, "tag name '%s' unrecognized for pkg declaration", tag.Name).WithFix("use one of @package, @description, @usage or @dependency"), tag.Pos)
 						}
 					}
 				}
-				// Ensure the pkg name represents an actual pkg in the src code
-				found := false
-				for _, name := range pkgNames {
-					if pkg.Name == name {
-						found = true
-					}
+				// A PKG block documents the package of the file it is written in
+				pkg.ImportPath = comment.ImportPath
+				pkg.Dir = filepath.Dir(comment.File)
+				if pkg.Name == "" {
+					pkg.Name = comment.Package
 				}
-				if !found {
-					p.Errors = append(p.Errors, fmt.Errorf("package name '%s' does not represent a real package in the code", pkg.Name))
+				if pkg.Name != comment.Package {
+					p.report(models.Errorf(models.CodePackageMismatch, "package name '%s' does not match package '%s' declared in this file", pkg.Name, comment.Package).WithFix(fmt.Sprintf("change the package name to '%s' or leave it out", comment.Package)), comment.Pos)
 				} else {
 					// Ensure no duplicate pkg declarations
-					found = false
+					found := false
 					for _, p := range p.Packages {
-						if pkg.Name == p.Name {
+						if pkg.ImportPath == p.ImportPath {
 							found = true
 						}
 					}
 					if found {
-						p.Errors = append(p.Errors, fmt.Errorf("duplicate package declartion for '%s'", pkg.Name))
+						p.report(models.Errorf(models.CodeDuplicatePackage, "duplicate package declartion for '%s'", pkg.ImportPath).WithFix("keep a single PKG block per package"), comment.Pos)
 					} else {
 						p.Packages = append(p.Packages, pkg)
 					}
 				}
 			case "FILE":
 			case "TYPE", "T":
+			case "INTERFACE", "IFACE":
 			case "FUNCTION", "FUNC":
 			case "VARIABLE", "VAR", "V":
+			case "CONST", "ENUM":
 			default:
-				p.Errors = append(p.Errors, fmt.Errorf("unrecognized header '%s'", keyword))
+				p.report(models.Errorf(models.CodeUnknownHeader, "unrecognized header '%s'", keyword).WithFix("use one of -- PACKAGE, -- FILE, -- TYPE, -- INTERFACE, -- FUNC, -- VAR or -- CONST"), comment.Pos)
 			}
 		}
 	}
@@ -340,27 +435,29 @@ func (p *Parser) initializeFiles(comments []models.Comment) {
 	for _, comment := range comments {
 		// Get the header from the comment block
 		headerLine := comment.Text[0]
-		keyword, err := extractKeyword(headerLine, comment.File)
+		keyword, err := extractKeyword(headerLine)
 		// fmt.Printf("%s\n", keyword)
 		if err != nil {
-			p.Errors = append(p.Errors, err)
+			// Already reported while initializing packages
+			continue
 		} else {
 			// Only want to evalute if there is a valid
-			text := strings.Join(comment.Text[1:], "\n") // All of the comment except the header line
 			// Retreive comment information according to block type
 			switch keyword {
 			case "FILE":
 				var file models.File
 				file.Path = comment.File
-				tags, err := p.extractTagData(text)
+				file.Pos = comment.Pos
+				file.Build = p.builds[comment.File]
+				tags, err := extractCommentTags(comment)
 				if err != nil {
-					p.Errors = append(p.Errors, err)
+					p.report(err, comment.Pos)
 				} else {
 					for _, tag := range tags {
 						// fmt.Printf("%s: %s\n", tag.Name, tag.Content)
 						if tag.Name == "file" || tag.Name == "f" || tag.Name == "name" {
 							file.Name = tag.Content
-						} else if tag.Name == "description" || tag.Name == "desc" {
+						} else if tag.Name == "description" || tag.Name == "desc" || tag.Name == "brief" {
 							file.Desc = tag.Content
 						} else if tag.Name == "author" || tag.Name == "auth" || tag.Name == "a" {
 							file.Author = tag.Content
@@ -369,7 +466,7 @@ func (p *Parser) initializeFiles(comments []models.Comment) {
 						} else if tag.Name == "date" || tag.Name == "d" {
 							file.Date = tag.Content
 						} else {
-							p.Errors = append(p.Errors, fmt.Errorf("tag name '%s' unrecognized for file declaration", tag.Name))
+							p.report(models.Errorf(models.CodeUnknownTag, "tag name '%s' unrecognized for file declaration", tag.Name).WithFix("use one of @file, @description, @author, @version or @date"), tag.Pos)
 						}
 					}
 				}
@@ -377,21 +474,21 @@ func (p *Parser) initializeFiles(comments []models.Comment) {
 				// Allocate the file to its pkg
 				found := false
 				for i := range p.Packages {
-					if p.Packages[i].Name == comment.Package {
+					if p.Packages[i].ImportPath == comment.ImportPath {
 						found = true
 						p.Packages[i].Files = append(p.Packages[i].Files, file)
 					}
 				}
 
 				if !found {
-					p.Errors = append(p.Errors, fmt.Errorf("no package found for file '%s'", file.Path))
+					p.report(models.Errorf(models.CodeNoPackage, "no package found for file '%s'", file.Path).WithFix("add a '-- PKG' block to one of the package's files"), comment.Pos)
 				}
 			}
 		}
 	}
 }
 
-func extractKeyword(line, filePath string) (string, error) {
+func extractKeyword(line string) (string, error) {
 	// Trim spaces and check if the line starts with `-- `
 	line = strings.TrimSpace(line)
 	if strings.HasPrefix(line, "-- ") {
@@ -399,171 +496,212 @@ func extractKeyword(line, filePath string) (string, error) {
 		// Remove the `-- ` prefix
 		keyword = strings.TrimSpace(strings.TrimPrefix(line, "-- "))
 		if keyword == "" {
-			return "", fmt.Errorf("extracted keyword is blank")
+			return "", models.Errorf(models.CodeMalformedHeader, "extracted keyword is blank").WithFix("name the block type after '-- ', e.g. '-- FUNC'")
 		}
 		// Further trim spaces around the keyword
 		return keyword, nil
 	} else {
-		return "", fmt.Errorf("expected '-- ' before comment block type in '%s': '%s'", filePath, line)
+		return "", models.Errorf(models.CodeMalformedHeader, "expected '-- ' before comment block type: '%s'", line).WithFix("start the block with a header line such as '-- FUNC'")
 	}
 }
 
-func (p *Parser) extractTagData(text string) ([]models.Tag, error) {
+// extractTagData splits a block's text into tags. A tag starts with '@' at the start of a line or after whitespace,
+// '\@' is a literal '@', and nothing inside a `code span` or a fenced code block starts a tag.
+// Anything before the first tag is ignored, and each tag's content is laid out by formatText.
+// Tags are positioned by their offset in text as it was passed in.
+func extractTagData(text string) ([]models.Tag, error) {
 	var buffer strings.Builder
 	var tags []models.Tag
-	name, content := "", ""
+	var tag *models.Tag // Tag being read, nil until the first one is found
+	inSpan, inFence := false, false
+	trimmed := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace)) // Blank lines before the first tag, which offsets must still count
 	text = strings.TrimSpace(text)
-	p.src = text
-	p.resetState()
+	l := newLexer(text)
 	// Optionally, print the line under examination
-	// fmt.Printf("%s\n", p.src)
-
-	for p.ch != 0 {
-		if p.ch == '@' {
-			p.readChar() // Skip @
-			for p.ch != ' ' {
-				buffer.WriteByte(p.ch)
-				p.readChar()
+	// fmt.Printf("%s\n", l.src)
+
+	// finishTag stores the tag being read, with everything since its name as its content
+	finishTag := func() error {
+		if tag == nil {
+			return nil
+		}
+		tag.Content = formatText(strings.TrimSpace(buffer.String()))
+		if tag.Content == "" {
+			return models.Errorf(models.CodeBlankTag, "tag '@%s' has no content", tag.Name)
+		}
+		tags = append(tags, *tag)
+		return nil
+	}
+
+	for l.ch != 0 {
+		switch {
+		case l.atLineStart() && strings.HasPrefix(strings.TrimLeft(l.src[l.position:], " \t"), "```"):
+			// Fenced code blocks are copied as they are
+			inFence = !inFence
+			buffer.WriteByte(l.ch)
+			l.readChar()
+		case l.ch == '\n':
+			inSpan = false // Code spans end with their line
+			buffer.WriteByte(l.ch)
+			l.readChar()
+		case inFence:
+			buffer.WriteByte(l.ch)
+			l.readChar()
+		case l.ch == '`':
+			inSpan = !inSpan
+			buffer.WriteByte(l.ch)
+			l.readChar()
+		case inSpan:
+			buffer.WriteByte(l.ch)
+			l.readChar()
+		case l.ch == '\\' && l.peekChar(0) == '@':
+			buffer.WriteByte('@')
+			l.advanceBy(2)
+		case l.ch == '@' && l.afterWhitespace():
+			if err := finishTag(); err != nil {
+				return tags, err
 			}
-			p.readChar() // Skip ' '
-			if len(buffer.String()) > 0 {
-				name = strings.ToLower(strings.TrimSpace(buffer.String()))
-			} else {
-				return tags, fmt.Errorf("blank tag name")
+			buffer.Reset()
+
+			start := l.position
+			l.readChar() // Skip @
+			for l.ch != 0 && !isWhitespace(l.ch) {
+				buffer.WriteByte(l.ch)
+				l.readChar()
 			}
+			name := strings.ToLower(buffer.String())
 			buffer.Reset()
+			if name == "" {
+				return tags, models.Errorf(models.CodeBlankTag, "blank tag name").WithFix("write '\\@' for a literal '@'")
+			}
 
 			// fmt.Printf("%s\n", name)
 
-			// Tag name found, read content until another tag declaration (@)
-			for p.ch != '@' && p.ch != 0 {
-				buffer.WriteByte(p.ch)
-				p.readChar()
-			}
-			if len(buffer.String()) > 0 {
-				content = strings.TrimSpace(buffer.String())
-				tags = append(tags, models.Tag{Name: name, Content: content})
-			} else {
-				return tags, fmt.Errorf("blank tag name")
-			}
-			buffer.Reset()
-		} else {
-			p.readChar()
+			// Tag name found, its content runs until the next tag
+			tag = &models.Tag{Name: name, Pos: models.Position{Offset: trimmed + start}}
+		default:
+			buffer.WriteByte(l.ch)
+			l.readChar()
 		}
 	}
+	if err := finishTag(); err != nil {
+		return tags, err
+	}
 
 	return tags, nil
 }
 
-func (p *Parser) extractDependency(content string) (models.Dependency, error) {
+func extractDependency(content string) (models.Dependency, error) {
 	var dep models.Dependency
 	var buffer strings.Builder
 
-	p.src = content
-	// fmt.Printf("%s\n", p.src)
-
-	p.resetState()
+	l := newLexer(content)
 
 	if strings.HasPrefix(content, "(") {
-		p.readChar()
+		l.readChar()
 		//Extract dep name
-		for p.ch != 0 && p.ch != ')' {
-			buffer.WriteByte(p.ch)
-			p.readChar()
+		for l.ch != 0 && l.ch != ')' {
+			buffer.WriteByte(l.ch)
+			l.readChar()
 		}
 		if len(buffer.String()) > 0 {
 			dep.Name = buffer.String()
 		} else {
-			return dep, fmt.Errorf("blank tag name")
+			return dep, models.Errorf(models.CodeBlankTag, "blank dependency name")
 		}
 		buffer.Reset()
-		p.readChar()
-		p.readChar()
+		l.readChar()
+		l.readChar()
 		// Extract dep description
-		for p.ch != 0 {
-			buffer.WriteByte(p.ch)
-			p.readChar()
+		for l.ch != 0 {
+			buffer.WriteByte(l.ch)
+			l.readChar()
 		}
 		if len(buffer.String()) > 0 {
 
 			dep.Desc = strings.TrimSpace(buffer.String())
 		} else {
-			return dep, fmt.Errorf("dependency description for '%s' is blank", dep.Name)
+			return dep, models.Errorf(models.CodeMalformedTag, "dependency description for '%s' is blank", dep.Name)
 		}
 		buffer.Reset()
 	} else {
-		return dep, fmt.Errorf("expected '(dependencyName) Description about depencency'")
+		return dep, models.Errorf(models.CodeMalformedTag, "expected '(dependencyName) Description about depencency'")
 	}
 
 	return dep, nil
 }
 
-func (p *Parser) extractVarContent(content string) (models.Var, error) {
+// extractVarContent reads 'name (type): description'. The type can be left out and taken from the declaration,
+// and the ':' can be left out as in Javadoc's '@param name description'. pos is where content starts in the source.
+func extractVarContent(content string, pos models.Position) (models.Var, error) {
 	var _var models.Var
 	var buffer strings.Builder
 	content = strings.TrimSpace(content)
-	p.src = content
-	p.resetState()
+	l := newLexer(content)
 
 	// Get the variable name first
-	for p.ch != 0 && p.ch != '(' {
-		buffer.WriteByte(p.ch)
-		p.readChar()
+	for l.ch != 0 && l.ch != '(' && l.ch != ':' && !isWhitespace(l.ch) {
+		buffer.WriteByte(l.ch)
+		l.readChar()
 	}
 	_var.Name = strings.TrimSpace(buffer.String())
 	buffer.Reset()
-	p.readChar() // Consume '('
+	if _var.Name == "" {
+		return _var, models.Errorf(models.CodeMalformedTag, "expected format: 'myVar (string): Description of myVar', 'myVar: Description of myVar' or 'myVar Description of myVar'")
+	}
+	l.skipWhitespace()
 
-	// Get the variable type next
-	for p.ch != 0 && p.ch != ')' {
-		buffer.WriteByte(p.ch)
-		p.readChar()
+	// Get the variable type next, it can be left out and taken from the declaration instead
+	if l.ch == '(' {
+		typePos := shiftPosition(pos, l.position)
+		typeName, err := readParenthesized(l)
+		if err != nil {
+			return _var, atPosition(err, typePos)
+		}
+		if strings.TrimSpace(typeName) != "" {
+			if _var.Type, err = parseTypeAnnotation(typeName); err != nil {
+				return _var, atPosition(err, shiftPosition(typePos, 1))
+			}
+		}
+		l.skipWhitespace()
 	}
-	_var.Type = strings.TrimSpace(buffer.String())
-	buffer.Reset()
-	p.readChar() // Consume ')'
 
-	if p.ch != ':' {
-		return _var, fmt.Errorf("expected format: 'myVar (string): Description of myVar'")
+	if l.ch == ':' {
+		l.readChar() // Consume ':'
 	}
-	p.readChar() // Consume ':'
-	p.skipWhitespace()
+	l.skipWhitespace()
 
-	// Get the variable type next
-	for p.ch != 0 {
-		buffer.WriteByte(p.ch)
-		p.readChar()
+	// Everything left is the description
+	for l.ch != 0 {
+		buffer.WriteByte(l.ch)
+		l.readChar()
 	}
 	_var.Desc = strings.TrimSpace(buffer.String())
 	buffer.Reset()
-	p.readChar() // Consume ')'
 
 	return _var, nil
 }
 
-func (p *Parser) extractSpecialComment(content, filePath string) (models.ReturnResponse, error) {
+// extractSpecialComment reads '(val1) val2', where val1 may itself contain balanced brackets
+func extractSpecialComment(content string) (models.ReturnResponse, error) {
 	var obj models.ReturnResponse
 	var buffer strings.Builder
 	content = strings.TrimSpace(content)
-	p.src = content
-	p.resetState()
+	l := newLexer(content)
 
-	if p.ch != '(' {
-		return obj, fmt.Errorf("expected '(val1) val2' in file '%s': '%s'", filePath, content)
+	if l.ch != '(' {
+		return obj, models.Errorf(models.CodeMalformedTag, "expected '(val1) val2': '%s'", content)
 	}
-	p.readChar()
 
-	for p.ch != 0 && p.ch != ')' {
-		buffer.WriteByte(p.ch)
-		p.readChar()
+	paren, err := readParenthesized(l)
+	if err != nil {
+		return obj, err
 	}
-	obj.Paren = buffer.String()
-	buffer.Reset()
-	p.readChar()
+	obj.Paren = paren
 
-	for p.ch != 0 {
-		buffer.WriteByte(p.ch)
-		p.readChar()
+	for l.ch != 0 {
+		buffer.WriteByte(l.ch)
+		l.readChar()
 	}
 	obj.Desc = buffer.String()
 	buffer.Reset()
@@ -571,116 +709,26 @@ func (p *Parser) extractSpecialComment(content, filePath string) (models.ReturnR
 	return obj, nil
 }
 
-func (p *Parser) resetState() {
-	p.position = 0
-	p.readPosition = 0
-	p.readChar()
-}
-
-func (p *Parser) readFile(file *os.File) (string, error) {
-	var sb strings.Builder
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		sb.WriteString(scanner.Text() + "\n")
-	}
-	if err := scanner.Err(); err != nil {
-		return "", err
+// extractReturnContent reads '(type) description', checking that the type is a Go type.
+// pos is where content starts in the source.
+func extractReturnContent(content string, pos models.Position) (models.ReturnResponse, error) {
+	pos = shiftPosition(pos, len(content)-len(strings.TrimLeft(content, " \t")))
+	ret, err := extractSpecialComment(content)
+	if err != nil {
+		return ret, atPosition(err, pos)
 	}
-	return sb.String(), nil
-}
-
-func (p *Parser) extractPkgName() string {
-	lines := strings.Split(p.src, "\n")
-	for _, line := range lines {
-		// Check for the line that starts with "package "
-		if strings.HasPrefix(strings.TrimSpace(line), "package ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "package "))
+	if strings.TrimSpace(ret.Paren) != "" {
+		if ret.Paren, err = parseTypeAnnotation(ret.Paren); err != nil {
+			return ret, atPosition(err, shiftPosition(pos, 1))
 		}
 	}
-	return ""
-}
-
-func (p *Parser) extractBlockComment(filePath, pkgName string) models.Comment {
-	var lines []string
-	p.advanceBy(4) // Skip /***
-
-	for !p.isAtEnd() {
-		p.skipWhitespace()
-
-		// Check for the end of the block comment */
-		if p.ch == '*' && p.peekChar(0) == '/' {
-			p.advanceBy(2) // Skip closing */
-			break
-		}
-
-		// Read and store each line
-		var sb strings.Builder
-		for p.ch != '\n' && p.ch != 0 {
-			sb.WriteByte(p.ch)
-			p.readChar()
-		}
-
-		line := strings.TrimSpace(sb.String())
-		if len(line) > 0 {
-			lines = append(lines, line)
-		}
-
-		p.readChar() // Move to the next line
-	}
-
-	if len(lines) == 0 {
-		p.Errors = append(p.Errors, fmt.Errorf("no lines in file %s", filePath))
-		return models.Comment{}
-	}
-
-	return models.Comment{
-		File:    filePath,
-		Package: pkgName,
-		Text:    lines,
-	}
-}
-
-func (p *Parser) advanceBy(n int) {
-	for i := 0; i < n; i++ {
-		p.readChar()
-	}
-}
-
-func (p *Parser) isGoDocComment() bool {
-	return p.ch == '/' && p.peekChar(0) == '*' && p.peekChar(1) == '*' && p.peekChar(2) == '*'
-}
-
-func (p *Parser) readChar() {
-	if p.readPosition >= len(p.src) { // Check if the end of input is reached
-		p.ch = 0 // Null character indicating end of input
-	} else {
-		p.ch = p.src[p.readPosition] // Read the current character
-	}
-	p.position = p.readPosition // Update the current position
-	p.readPosition += 1         // Move to the next character
-}
-
-func (p *Parser) peekChar(ahead int) byte {
-	if p.readPosition+ahead >= len(p.src) {
-		return 0
-	}
-	return p.src[p.readPosition+ahead]
-}
-
-func (p *Parser) skipWhitespace() {
-	for p.ch == ' ' || p.ch == '\t' || p.ch == '\n' || p.ch == '\r' {
-		p.readChar()
-	}
+	return ret, nil
 }
 
 func isEmptyComment(comment models.Comment) bool {
 	return len(comment.Text) == 0
 }
 
-func (p *Parser) isAtEnd() bool {
-	return p.readPosition >= len(p.src)
-}
-
 func contains(slice []string, item string) bool {
 	for _, v := range slice {
 		if v == item {
@@ -702,8 +750,8 @@ func (p *Parser) writeToJson() error {
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 
-	// Write the Packages data to the file
-	err = encoder.Encode(p.Packages)
+	// Write the Modules data to the file
+	err = encoder.Encode(p.Modules)
 	if err != nil {
 		return fmt.Errorf("error encoding JSON data: %v", err)
 	}
@@ -0,0 +1,1486 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// parseFixture runs a full save over the test/ example project, including everything unexported.
+// The JSON output and cache are written to a temporary directory so the source tree stays clean.
+func parseFixture(t *testing.T) *Parser {
+	t.Helper()
+	return parseFixtureWith(t, models.Settings{IncludeTests: true, IncludePrivateFuncs: true, IncludePrivateVars: true, IncludePrivateTypes: true})
+}
+
+// parseFixtureWith runs a full save over the test/ example project with the given settings
+func parseFixtureWith(t *testing.T, settings models.Settings) *Parser {
+	t.Helper()
+	fixture, err := filepath.Abs(filepath.Join("..", "..", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdirTemp(t)
+	return save(fixture, settings, true)
+}
+
+// parseProject runs a full save over a project made of the given files, keyed by their slash-separated path in it
+func parseProject(t *testing.T, settings models.Settings, files map[string]string) *Parser {
+	t.Helper()
+	root := t.TempDir()
+	writeFiles(t, root, files)
+	chdirTemp(t)
+	return save(root, settings, true)
+}
+
+// writeFiles writes files, keyed by their slash-separated path, under root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// chdirTemp moves the test into a temporary directory for as long as it runs, so saves write their output there
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// save runs a save over the project at root, using the cache in the working directory unless force is set
+func save(root string, settings models.Settings, force bool) *Parser {
+	settings.ProjectPath = root
+	p := New(settings)
+	p.Force = force
+	p.ParseProject()
+	return p
+}
+
+func findPackage(t *testing.T, p *Parser, importPath string) models.Package {
+	t.Helper()
+	for _, mod := range p.Modules {
+		for _, pkg := range mod.Packages {
+			if pkg.ImportPath == importPath {
+				return pkg
+			}
+		}
+	}
+	t.Fatalf("package '%s' not found", importPath)
+	return models.Package{}
+}
+
+func TestExtractTagData(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []models.Tag
+	}{
+		{
+			name: "tags",
+			text: "@func Foo\n@desc Does foo.",
+			want: []models.Tag{{Name: "func", Content: "Foo"}, {Name: "desc", Content: "Does foo."}},
+		},
+		{
+			name: "tag names are lowercased",
+			text: "@DESC Does foo.",
+			want: []models.Tag{{Name: "desc", Content: "Does foo."}},
+		},
+		{
+			name: "tags after whitespace on the same line",
+			text: "@func Foo @desc Does foo.",
+			want: []models.Tag{{Name: "func", Content: "Foo"}, {Name: "desc", Content: "Does foo."}},
+		},
+		{
+			name: "@ inside a word",
+			text: "@auth John Smith <john@example.com>",
+			want: []models.Tag{{Name: "auth", Content: "John Smith <john@example.com>"}},
+		},
+		{
+			name: "escaped @",
+			text: "@desc Ask \\@dba-team for access.",
+			want: []models.Tag{{Name: "desc", Content: "Ask @dba-team for access."}},
+		},
+		{
+			name: "@ inside a code span",
+			text: "@desc Install with `go get example.com/mod @latest`.",
+			want: []models.Tag{{Name: "desc", Content: "Install with `go get example.com/mod @latest`."}},
+		},
+		{
+			name: "code spans end with their line",
+			text: "@desc Unclosed ` span\n@v 1.0",
+			want: []models.Tag{{Name: "desc", Content: "Unclosed ` span"}, {Name: "v", Content: "1.0"}},
+		},
+		{
+			name: "@ inside a fenced code block",
+			text: "@desc Example:\n```\n@decorator\ncall()\n```\n@v 1.0",
+			want: []models.Tag{{Name: "desc", Content: "Example:\n\n```\n@decorator\ncall()\n```"}, {Name: "v", Content: "1.0"}},
+		},
+		{
+			name: "text before the first tag",
+			text: "stray text @func Foo",
+			want: []models.Tag{{Name: "func", Content: "Foo"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tags, err := extractTagData(test.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tags) != len(test.want) {
+				t.Fatalf("got %d tags %+v, want %d", len(tags), tags, len(test.want))
+			}
+			for i := range tags {
+				if tags[i].Name != test.want[i].Name || tags[i].Content != test.want[i].Content {
+					t.Errorf("tag %d = @%s %q, want @%s %q", i, tags[i].Name, tags[i].Content, test.want[i].Name, test.want[i].Content)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "single line",
+			content: "Does foo.",
+			want:    "Does foo.",
+		},
+		{
+			name:    "wrapped lines are joined",
+			content: "Does foo\n  and then bar.",
+			want:    "Does foo and then bar.",
+		},
+		{
+			name:    "paragraphs",
+			content: "First paragraph\nstill first.\n\n\nSecond paragraph.",
+			want:    "First paragraph still first.\n\nSecond paragraph.",
+		},
+		{
+			name:    "lists",
+			content: "Steps:\n- one\n  wrapped\n  - nested\n2. two",
+			want:    "Steps:\n\n- one wrapped\n  - nested\n2. two",
+		},
+		{
+			name:    "indented code",
+			content: "Example:\n\n    x := foo()\n\n    bar(x)\n\nAfter.",
+			want:    "Example:\n\n```\nx := foo()\n\nbar(x)\n```\n\nAfter.",
+		},
+		{
+			name:    "indented lines continue a paragraph",
+			content: "Example:\n    not code",
+			want:    "Example: not code",
+		},
+		{
+			name:    "fenced code keeps its layout",
+			content: "Example:\n```go\nif x {\n\treturn\n}\n```",
+			want:    "Example:\n\n```go\nif x {\n\treturn\n}\n```",
+		},
+		{
+			name:    "unclosed fence",
+			content: "```\ncode",
+			want:    "```\ncode\n```",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatText(test.content); got != test.want {
+				t.Errorf("formatText(%q) = %q, want %q", test.content, got, test.want)
+			}
+		})
+	}
+}
+
+func TestExtractTagDataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "blank tag name", text: "@desc Costs 5 @ 10 each."},
+		{name: "blank content", text: "@func Foo\n@desc"},
+		{name: "tag name at the end", text: "@func"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := extractTagData(test.text)
+			diag, ok := err.(models.Diagnostic)
+			if !ok || diag.Code != models.CodeBlankTag {
+				t.Fatalf("got error %v, want a %s diagnostic", err, models.CodeBlankTag)
+			}
+		})
+	}
+}
+
+func TestExtractVarContentTypes(t *testing.T) {
+	tests := []struct {
+		content  string
+		wantType string
+		wantDesc string
+	}{
+		{content: "fn (func(int) error): Called for each row.", wantType: "func(int) error", wantDesc: "Called for each row."},
+		{content: "routes (map[string][]func()): Handlers by path.", wantType: "map[string][]func()", wantDesc: "Handlers by path."},
+		{content: "opts (struct{ Name string `json:\"a)b\"` }): Options.", wantType: "struct{Name string}", wantDesc: "Options."},
+		{content: "args ( ...  string ): Extra arguments.", wantType: "...string", wantDesc: "Extra arguments."},
+		{content: "set (Set[ int ]): Generic type.", wantType: "Set[int]", wantDesc: "Generic type."},
+		{content: "id (): Type taken from the declaration.", wantType: "", wantDesc: "Type taken from the declaration."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.content, func(t *testing.T) {
+			_var, err := extractVarContent(test.content, models.Position{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _var.Type != test.wantType || _var.Desc != test.wantDesc {
+				t.Errorf("got type %q, desc %q, want type %q, desc %q", _var.Type, _var.Desc, test.wantType, test.wantDesc)
+			}
+		})
+	}
+}
+
+func TestExtractVarContentMalformedTypes(t *testing.T) {
+	pos := models.Position{File: "a.go", Offset: 100, Line: 5, Column: 12}
+	tests := []struct {
+		content    string
+		wantColumn int
+	}{
+		{content: "fn (func(int) error: Missing the closing paren.", wantColumn: 15},
+		{content: "m (map[string)int): Unbalanced.", wantColumn: 14},
+		{content: "n (1 + 2): Not a type.", wantColumn: 15},
+		{content: "x (map[string]): Missing the value type.", wantColumn: 15},
+	}
+
+	for _, test := range tests {
+		t.Run(test.content, func(t *testing.T) {
+			_, err := extractVarContent(test.content, pos)
+			diag, ok := err.(models.Diagnostic)
+			if !ok || diag.Code != models.CodeMalformedType {
+				t.Fatalf("got error %v, want a %s diagnostic", err, models.CodeMalformedType)
+			}
+			if diag.Pos.File != "a.go" || diag.Pos.Line != 5 || diag.Pos.Column != test.wantColumn {
+				t.Errorf("diagnostic at %v, want a.go:5:%d", diag.Pos, test.wantColumn)
+			}
+		})
+	}
+}
+
+func TestTagPositionsAfterBlankLine(t *testing.T) {
+	// Blank lines after the header are kept in a block's text, and must still count towards the tags' lines
+	tests := []struct {
+		name   string
+		src    string
+		column int // Column the tags start at
+	}{
+		{
+			name:   "plain",
+			column: 5,
+			src:    "package a\n\n/***\n    -- FUNC\n\n    @func Foo\n    @param x (int]): Bad type.\n*/\nfunc Foo(x int) {}\n",
+		},
+		{
+			name:   "decorated",
+			column: 4,
+			src:    "package a\n\n/***\n * -- FUNC\n *\n * @func Foo\n * @param x (int]): Bad type.\n */\nfunc Foo(x int) {}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := New(models.Settings{})
+			f := &sourceFile{path: "a.go", content: test.src, hash: "test"}
+			p.readSourceFile(f, cachedFile{}, false)
+			if len(f.comments) != 1 {
+				t.Fatalf("got %d blocks, want 1", len(f.comments))
+			}
+			tags, err := extractCommentTags(f.comments[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tags) != 2 {
+				t.Fatalf("got %d tags %+v, want 2", len(tags), tags)
+			}
+			if pos := tags[0].Pos; pos.Line != 6 || pos.Column != test.column {
+				t.Errorf("@func at %d:%d, want 6:%d", pos.Line, pos.Column, test.column)
+			}
+			if pos := tags[1].Pos; pos.Line != 7 || pos.Column != test.column {
+				t.Errorf("@param at %d:%d, want 7:%d", pos.Line, pos.Column, test.column)
+			}
+			// The type's diagnostic points at its '(', after '@param x '
+			_, err = extractVarContent(tags[1].Content, contentPosition(tags[1]))
+			if diag, ok := err.(models.Diagnostic); !ok || diag.Pos.Line != 7 || diag.Pos.Column != test.column+9 {
+				t.Errorf("got error %v, want one at a.go:7:%d", err, test.column+9)
+			}
+		})
+	}
+}
+
+func TestFixtureTagGrammar(t *testing.T) {
+	p := parseFixture(t)
+	db := findPackage(t, p, "github.com/ajtroup1/GoDocsExample/db")
+
+	if len(db.Files) != 1 || db.Files[0].Author != "John Smith <john@example.com>" {
+		t.Errorf("db.go author = %+v, want 'John Smith <john@example.com>'", db.Files)
+	}
+	if len(db.Files) == 1 && db.Files[0].Version != "1.0" {
+		t.Errorf("db.go version = %q, want '1.0'", db.Files[0].Version)
+	}
+
+	if len(db.Funcs) != 1 {
+		t.Fatalf("got %d funcs in db, want 1", len(db.Funcs))
+	}
+	desc := db.Funcs[0].Desc
+	for _, want := range []string{
+		"user:password@tcp(127.0.0.1:3306)/mydatabase",
+		"`go get github.com/go-sql-driver/mysql@latest`",
+		"ask @dba-team for credentials",
+	} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("NewConnection description %q is missing %q", desc, want)
+		}
+	}
+	if len(db.Funcs[0].Returns) != 2 {
+		t.Errorf("got %d return values for NewConnection, want 2", len(db.Funcs[0].Returns))
+	}
+}
+
+func TestFixtureHasNoTagErrors(t *testing.T) {
+	p := parseFixture(t)
+	for _, diag := range p.Diagnostics {
+		switch diag.Code {
+		case models.CodeUnknownTag, models.CodeMalformedTag, models.CodeBlankTag:
+			t.Errorf("unexpected tag diagnostic: %v", diag)
+		}
+	}
+	if models.HasErrors(p.Diagnostics) {
+		t.Errorf("fixture reported errors: %v", p.Diagnostics)
+	}
+}
+
+func TestFixturePackages(t *testing.T) {
+	p := parseFixture(t)
+	// Free funcs, then each type with its constructors and methods
+	want := map[string][]string{
+		"github.com/ajtroup1/GoDocsExample/db":               {"NewConnection"},
+		"github.com/ajtroup1/GoDocsExample/internal/handler": {"UserHandler: NewUserHandler | GetAllUsers, GetUserByID"},
+		"github.com/ajtroup1/GoDocsExample/internal/repo":    {"UserRepository: NewUserRepository | GetAllUsers, GetUserByID"},
+		"github.com/ajtroup1/GoDocsExample/internal/service": {"UserService: NewUserService | GetAllUsers, GetUserByID"},
+	}
+	for importPath, layout := range want {
+		pkg := findPackage(t, p, importPath)
+		var got []string
+		for _, function := range pkg.Funcs {
+			got = append(got, function.Name)
+		}
+		for _, _type := range pkg.Types {
+			if len(_type.Constructors) == 0 && len(_type.Methods) == 0 {
+				continue
+			}
+			var constructors, methods []string
+			for _, function := range _type.Constructors {
+				constructors = append(constructors, function.Name)
+			}
+			for _, function := range _type.Methods {
+				methods = append(methods, function.Name)
+			}
+			got = append(got, _type.Name+": "+strings.Join(constructors, ", ")+" | "+strings.Join(methods, ", "))
+		}
+		if strings.Join(got, "; ") != strings.Join(layout, "; ") {
+			t.Errorf("%s layout = %q, want %q", importPath, got, layout)
+		}
+	}
+}
+
+func findPackageType(t *testing.T, pkg models.Package, name string) models.Type {
+	t.Helper()
+	for _, _type := range pkg.Types {
+		if _type.Name == name {
+			return _type
+		}
+	}
+	t.Fatalf("type '%s' not found in %s", name, pkg.ImportPath)
+	return models.Type{}
+}
+
+func TestFixtureMultilineDescription(t *testing.T) {
+	p := parseFixture(t)
+	repo := findPackage(t, p, "github.com/ajtroup1/GoDocsExample/internal/repo")
+
+	want := "Retrieves all users from the database, in the order the database returns them.\n\n" +
+		"Each row is scanned into a `model.User`:\n\n" +
+		"- `id` and `name` are required\n" +
+		"- `email` may be empty\n\n" +
+		"Example:\n\n" +
+		"```\nusers, err := repo.GetAllUsers()\n```"
+	for _, function := range findPackageType(t, repo, "UserRepository").Methods {
+		if function.Name == "GetAllUsers" {
+			if function.Desc != want {
+				t.Errorf("GetAllUsers description = %q, want %q", function.Desc, want)
+			}
+			return
+		}
+	}
+	t.Fatal("GetAllUsers not found on UserRepository")
+}
+
+func TestFixtureDialects(t *testing.T) {
+	p := parseFixture(t)
+	types := findPackage(t, p, "github.com/ajtroup1/GoDocsExample/internal/types")
+
+	var role *models.Type
+	for i := range types.Types {
+		if types.Types[i].Name == "Role" {
+			role = &types.Types[i]
+		}
+	}
+	if role == nil {
+		t.Fatal("Role not found in types")
+	}
+	if want := "Names what a user is allowed to do. Written with Javadoc-style decoration, which GoDoc strips."; role.Desc != want {
+		t.Errorf("Role description = %q, want %q", role.Desc, want)
+	}
+
+	user := findPackageType(t, types, "User")
+	if len(user.Constructors) != 1 || user.Constructors[0].Name != "NewUser" {
+		t.Fatalf("User constructors = %+v, want NewUser", user.Constructors)
+	}
+	newUser := user.Constructors[0]
+	if want := "Creates a user with the given details.\n\nThe user starts without a role; assign one before saving it."; newUser.Desc != want {
+		t.Errorf("NewUser description = %q, want %q", newUser.Desc, want)
+	}
+	var params []string
+	for _, param := range newUser.Params {
+		params = append(params, param.Name+" "+param.Type+": "+param.Desc)
+	}
+	want := "id int: The user's unique identifier.,name string: The user's name.,email string: The user's email address."
+	if strings.Join(params, ",") != want {
+		t.Errorf("NewUser params = %q, want %q", strings.Join(params, ","), want)
+	}
+	if len(newUser.Returns) != 1 || newUser.Returns[0].Paren != "User" {
+		t.Errorf("NewUser returns = %+v, want one User", newUser.Returns)
+	}
+}
+
+func TestDialectLicenseHeader(t *testing.T) {
+	pkgBlock := "/***\n    -- PKG\n    @desc Handles users.\n*/\npackage a\n"
+	tests := []struct {
+		name         string
+		src          string
+		wantBlocks   []string // Headers of the blocks read
+		wantWarnings int
+	}{
+		{
+			name:       "plain license header",
+			src:        "/** Copyright 2024 Example Inc.\n * @license MIT\n */\n\n" + pkgBlock,
+			wantBlocks: []string{"-- PKG"},
+		},
+		{
+			name:       "doxygen overview above a PKG block",
+			src:        "/**\n * @brief Example project.\n * @license MIT\n */\n\n" + pkgBlock,
+			wantBlocks: []string{"-- PKG"},
+		},
+		{
+			name:       "doxygen overview alone",
+			src:        "/**\n * @brief Example project.\n * @license MIT\n */\npackage a\n",
+			wantBlocks: []string{"-- PKG"}, wantWarnings: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := New(models.Settings{})
+			f := &sourceFile{path: "a.go", content: test.src, hash: "test"}
+			p.readSourceFile(f, cachedFile{}, false)
+
+			var headers []string
+			for _, comment := range f.comments {
+				headers = append(headers, comment.Text[0])
+			}
+			if strings.Join(headers, ",") != strings.Join(test.wantBlocks, ",") {
+				t.Errorf("blocks = %q, want %q", headers, test.wantBlocks)
+			}
+			if len(f.diagnostics) != test.wantWarnings {
+				t.Errorf("diagnostics = %v, want %d warnings", f.diagnostics, test.wantWarnings)
+			}
+			for _, diag := range f.diagnostics {
+				if diag.Severity != models.SeverityWarning || diag.Code != models.CodeUnknownTag {
+					t.Errorf("got %v, want a %s warning", diag, models.CodeUnknownTag)
+				}
+			}
+
+			// The package is declared once, without errors
+			p.parseComments(f.comments)
+			if models.HasErrors(p.Diagnostics) || len(p.Packages) != 1 {
+				t.Errorf("packages = %+v, diagnostics = %v", p.Packages, p.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestFixtureInterfaces(t *testing.T) {
+	p := parseFixture(t)
+	service := findPackage(t, p, "github.com/ajtroup1/GoDocsExample/internal/service")
+
+	if len(service.Interfaces) != 1 {
+		t.Fatalf("got %d interfaces in service, want 1", len(service.Interfaces))
+	}
+	store := service.Interfaces[0]
+	if store.Name != "UserStore" || store.Desc != "Reads users from wherever they are stored." {
+		t.Errorf("interface = %q %q, want UserStore with its description", store.Name, store.Desc)
+	}
+
+	var methods []string
+	for _, method := range store.Methods {
+		var params, returns []string
+		for _, param := range method.Params {
+			params = append(params, param.Name+" "+param.Type)
+		}
+		for _, ret := range method.Returns {
+			returns = append(returns, ret.Paren)
+		}
+		methods = append(methods, method.Name+"("+strings.Join(params, ", ")+") ("+strings.Join(returns, ", ")+")")
+	}
+	want := "GetAllUsers() ([]model.User, error),GetUserByID(id int) (model.User, error)"
+	if strings.Join(methods, ",") != want {
+		t.Errorf("UserStore methods = %q, want %q", strings.Join(methods, ","), want)
+	}
+
+	// Types without a block of their own count too, since their methods are documented under them
+	if strings.Join(store.Implementers, ",") != "*repository.UserRepository,*UserService" {
+		t.Errorf("UserStore implementers = %v, want [*repository.UserRepository *UserService]", store.Implementers)
+	}
+}
+
+func TestGenericMethodSets(t *testing.T) {
+	// Type parameters are declared by the receiver or the interface, not the package, so they stay unqualified
+	src := "package list\n\n" +
+		"type List[T any] struct{ items []T }\n\n" +
+		"func (l *List[T]) Push(v T) { l.items = append(l.items, v) }\n\n" +
+		"func (l *List[T]) Each(fn func(T) Item) {}\n\n" +
+		"type Pair[K comparable, V any] struct{}\n\n" +
+		"func (p Pair[K, V]) Get(key K) (V, *Item) { var v V; return v, nil }\n\n" +
+		"type Pusher[T any] interface{ Push(T) }\n\n" +
+		"type Item struct{}\n"
+	file, err := goparser.ParseFile(token.NewFileSet(), "list.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []*ast.File{file}
+
+	tests := []struct {
+		name string
+		got  []models.Method
+		want string
+	}{
+		{name: "List", got: typeMethodSet(files, "List", "example.com/list"), want: "Each(func(T) example.com/list.Item),Push(T)"},
+		{name: "Pair", got: typeMethodSet(files, "Pair", "example.com/list"), want: "Get(K) (V, *example.com/list.Item)"},
+		{name: "Pusher", got: interfaceMethodSet(files, findTypeSpec(files, "Pusher").Type.(*ast.InterfaceType), "example.com/list", typeParamNames(findTypeSpec(files, "Pusher").TypeParams), map[string]bool{}), want: "Push(T)"},
+	}
+	for _, test := range tests {
+		var methods []string
+		for _, method := range test.got {
+			methods = append(methods, method.Name+method.Signature)
+		}
+		if strings.Join(methods, ",") != test.want {
+			t.Errorf("%s method set = %q, want %q", test.name, strings.Join(methods, ","), test.want)
+		}
+	}
+}
+
+func TestFixtureConstGroups(t *testing.T) {
+	p := parseFixture(t)
+	types := findPackage(t, p, "github.com/ajtroup1/GoDocsExample/internal/types")
+
+	if len(types.Consts) != 1 {
+		t.Fatalf("got %d const groups in types, want 1", len(types.Consts))
+	}
+	status := types.Consts[0]
+	if status.Name != "Status" || status.Type != "Status" {
+		t.Errorf("const group = %q of type %q, want Status", status.Name, status.Type)
+	}
+	var values []string
+	for _, value := range status.Values {
+		values = append(values, value.Name+"="+value.Value+" "+value.Type+": "+value.Desc)
+	}
+	want := []string{
+		"StatusActive=1 Status: The user can sign in.",
+		"StatusSuspended=2 Status: The user is locked out until an admin restores the account.",
+		"StatusDeleted=3 Status: The account is gone for good.",
+		"StatusUnknown=16 Status: ",
+	}
+	if strings.Join(values, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Status values =\n%s\nwant\n%s", strings.Join(values, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Unexported groups belong to their file, and untyped constants have no type
+	var limits *models.ConstGroup
+	for _, file := range types.Files {
+		for i := range file.Consts {
+			if file.Consts[i].Name == "limits" {
+				limits = &file.Consts[i]
+			}
+		}
+	}
+	if limits == nil {
+		t.Fatal("limits not found in types.go")
+	}
+	if len(limits.Values) != 2 || limits.Values[1].Value != "256" || limits.Values[1].Type != "" {
+		t.Errorf("limits values = %+v, want maxEmailLength = 256 with no type", limits.Values)
+	}
+}
+
+func TestConstValues(t *testing.T) {
+	src := `package app
+
+/***
+-- PKG
+@pkg app
+@desc App.
+*/
+
+/***
+-- CONST
+@const Values
+@desc One of each kind of constant.
+*/
+
+const (
+	Ratio    float64 = 2.5
+	Third            = 1.0 / 3
+	Huge             = 1e100
+	Name             = "app"
+	Quote            = "say \"hi\"\n"
+	Letter   rune    = 'a'
+	Untyped          = 'é'
+	Code     int32   = 'b'
+	KB               = 1 << 10
+	Buffer           = KB * 4
+	Mask     uint8   = ^uint8(0) >> 4
+	Enabled          = true
+	Wave             = 1 + 2i
+	Whole    float64 = 3
+)
+`
+	p := parseProject(t, models.Settings{}, map[string]string{"go.mod": "module example.com/app\n\ngo 1.22\n", "app.go": src})
+	if models.HasErrors(p.Diagnostics) {
+		t.Fatalf("unexpected errors: %v", p.Diagnostics)
+	}
+	pkg := findPackage(t, p, "example.com/app")
+	if len(pkg.Consts) != 1 {
+		t.Fatalf("got %d const groups, want 1", len(pkg.Consts))
+	}
+
+	tests := []struct {
+		name, value, typeName string
+	}{
+		{"Ratio", "2.5", "float64"},
+		{"Third", "0.3333333333333333", ""},
+		{"Huge", "1e+100", ""},
+		{"Name", `"app"`, ""},
+		{"Quote", `"say \"hi\"\n"`, ""},
+		{"Letter", "'a'", "rune"},
+		{"Untyped", "'é'", ""},
+		{"Code", "98", "int32"},
+		{"KB", "1024", ""},
+		{"Buffer", "4096", ""},
+		{"Mask", "15", "uint8"},
+		{"Enabled", "true", ""},
+		{"Wave", "(1 + 2i)", ""},
+		{"Whole", "3", "float64"},
+	}
+	values := pkg.Consts[0].Values
+	if len(values) != len(tests) {
+		t.Fatalf("got %d values, want %d", len(values), len(tests))
+	}
+	for i, test := range tests {
+		if got := values[i]; got.Name != test.name || got.Value != test.value || got.Type != test.typeName {
+			t.Errorf("value %d = %s = %s (%s), want %s = %s (%s)", i, got.Name, got.Value, got.Type, test.name, test.value, test.typeName)
+		}
+	}
+}
+
+func TestFixturePublicAPI(t *testing.T) {
+	p := parseFixtureWith(t, models.Settings{ExcludePackages: []string{"github.com/ajtroup1/GoDocsExample/internal/...", "github.com/ajtroup1/*/cmd"}})
+
+	var importPaths []string
+	for _, mod := range p.Modules {
+		for _, pkg := range mod.Packages {
+			importPaths = append(importPaths, pkg.ImportPath)
+			for _, file := range pkg.Files {
+				if len(file.Funcs)+len(file.Vars)+len(file.Consts)+len(file.Types)+len(file.Interfaces) > 0 {
+					t.Errorf("%s still has unexported items", file.Path)
+				}
+			}
+		}
+	}
+	if strings.Join(importPaths, ",") != "github.com/ajtroup1/GoDocsExample/db" {
+		t.Errorf("packages = %q, want only the db package", importPaths)
+	}
+}
+
+func TestFixtureUnexportedConsts(t *testing.T) {
+	// Only exported constants are kept, and a bad pattern is reported rather than ignored
+	p := parseFixtureWith(t, models.Settings{ExcludePackages: []string{"[db"}})
+	types := findPackage(t, p, "github.com/ajtroup1/GoDocsExample/internal/types")
+	for _, file := range types.Files {
+		if len(file.Consts) != 0 {
+			t.Errorf("%s consts = %+v, want none", file.Path, file.Consts)
+		}
+	}
+	reported := false
+	for _, diagnostic := range p.Diagnostics {
+		if diagnostic.Code == models.CodeSettings {
+			reported = true
+		}
+	}
+	if !reported {
+		t.Error("malformed ExcludePackages pattern wasn't reported")
+	}
+}
+
+func TestFixtureTestDocComments(t *testing.T) {
+	// With IncludeTests set, doc comments in _test.go files are read like any other
+	p := parseFixtureWith(t, models.Settings{IncludeTests: true, IncludeDocComments: true})
+	handler := findPackage(t, p, "github.com/ajtroup1/GoDocsExample/internal/handler")
+	for _, function := range handler.Funcs {
+		if function.Name == "TestGetAllUsers" {
+			if function.Desc != "TestGetAllUsers checks that every stored user is returned as JSON." {
+				t.Errorf("desc = %q", function.Desc)
+			}
+			return
+		}
+	}
+	t.Errorf("TestGetAllUsers not harvested from handler_test.go, funcs = %+v", handler.Funcs)
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules, _ := ignoreRules(nil).add("", "", defaultIgnores)
+	rules, _ = rules.add("", ".godocignore", []string{"# comment", "*.pb.go", "/scratch/", "docs/**/draft", "!testdata/"})
+	rules, _ = rules.add("internal", "internal/.godocignore", []string{"legacy", "!keep.pb.go"})
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"vendor", true, true},
+		{"internal/vendor", true, true},
+		{"testdata", true, false},
+		{".git", true, true},
+		{"api/user.pb.go", false, true},
+		{"internal/keep.pb.go", false, false},
+		{"keep.pb.go", false, true},
+		{"scratch", true, true},
+		{"api/scratch", true, false},
+		{"scratch", false, false},
+		{"docs/draft", false, true},
+		{"docs/a/b/draft", true, true},
+		{"internal/legacy", true, true},
+		{"legacy", true, false},
+		{"internal/user.go", false, false},
+	}
+	for _, test := range tests {
+		if ignored, _ := rules.match(test.path, test.isDir); ignored != test.ignored {
+			t.Errorf("match(%q) ignored = %v, want %v", test.path, ignored, test.ignored)
+		}
+	}
+}
+
+func TestIgnoreClasses(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{pattern: "[abc].go", match: []string{"a.go", "dir/c.go"}, noMatch: []string{"d.go", "ab.go"}},
+		{pattern: "v[0-9].go", match: []string{"v1.go"}, noMatch: []string{"v.go", "va.go"}},
+		{pattern: "[!a-c].go", match: []string{"d.go", "_.go"}, noMatch: []string{"a.go", "b.go"}},
+		{pattern: "[^a-c].go", match: []string{"d.go"}, noMatch: []string{"c.go"}},
+		{pattern: "[]a]x", match: []string{"]x", "ax"}, noMatch: []string{"bx"}},
+		{pattern: "[!]a]x", match: []string{"bx"}, noMatch: []string{"]x", "ax"}},
+		{pattern: "[[:alpha:]]x", match: []string{"ax", "Zx"}, noMatch: []string{"1x", "_x"}},
+		{pattern: "[[:digit:]_]*.go", match: []string{"1main.go", "_gen.go"}, noMatch: []string{"main.go"}},
+		{pattern: `[\]]x`, match: []string{"]x"}, noMatch: []string{`\x`}},
+		{pattern: "[a^]x", match: []string{"^x", "ax"}, noMatch: []string{"bx"}},
+		{pattern: "a[!x]b", match: []string{"acb"}, noMatch: []string{"a/b", "axb"}},
+		{pattern: `\[x].go`, match: []string{"[x].go"}, noMatch: []string{"x.go"}},
+	}
+	for _, test := range tests {
+		rules, diagnostics := ignoreRules(nil).add("", ".godocignore", []string{test.pattern})
+		if len(diagnostics) != 0 || len(rules) != 1 {
+			t.Errorf("%q: diagnostics = %v", test.pattern, diagnostics)
+			continue
+		}
+		for _, path := range test.match {
+			if ignored, _ := rules.match(path, false); !ignored {
+				t.Errorf("%q doesn't match %q", test.pattern, path)
+			}
+		}
+		for _, path := range test.noMatch {
+			if ignored, _ := rules.match(path, false); ignored {
+				t.Errorf("%q matches %q", test.pattern, path)
+			}
+		}
+	}
+}
+
+// Malformed patterns are left out and reported at their line, while the rest of the file still applies
+func TestIgnoreMalformedPatterns(t *testing.T) {
+	lines := []string{"# header", "[abc", "*.pb.go", "[[:alpha]x", "[z-a].go", "[[:nope:]]", "[!"}
+	rules, diagnostics := ignoreRules(nil).add("api", "api/.godocignore", lines)
+
+	if len(rules) != 1 {
+		t.Errorf("got %d rules, want only '*.pb.go'", len(rules))
+	}
+	wantLines := []int{2, 4, 5, 6, 7}
+	if len(diagnostics) != len(wantLines) {
+		t.Fatalf("diagnostics = %v, want %d", diagnostics, len(wantLines))
+	}
+	for i, diag := range diagnostics {
+		if diag.Severity != models.SeverityError || diag.Code != models.CodeSettings {
+			t.Errorf("got %v, want a %s error", diag, models.CodeSettings)
+		}
+		if diag.Pos.File != "api/.godocignore" || diag.Pos.Line != wantLines[i] || !strings.Contains(diag.Message, lines[wantLines[i]-1]) {
+			t.Errorf("diagnostic %d = %v at %s, want line %d", i, diag.Message, diag.Pos, wantLines[i])
+		}
+	}
+}
+
+// A save over a project whose .godocignore has classes gitignore accepts, and one it doesn't, reports instead of crashing
+func TestIgnoreFileInProject(t *testing.T) {
+	p := parseProject(t, models.Settings{}, map[string]string{
+		"go.mod":       "module example.com/app\n\ngo 1.22\n",
+		".godocignore": "[[:alpha:]]x.go\n[]a]\n[unclosed\n",
+		"app.go":       "package app\n\n/***\n-- PKG\n@pkg app\n@desc App.\n*/\n",
+		"ax.go":        "package app\n\n/***\n-- FUNC\n@func Hidden\n@desc Ignored.\n*/\nfunc Hidden() {}\n",
+	})
+
+	if len(p.Diagnostics) != 1 || p.Diagnostics[0].Code != models.CodeSettings || p.Diagnostics[0].Pos.Line != 3 {
+		t.Fatalf("diagnostics = %v, want one for line 3", p.Diagnostics)
+	}
+	if pkg := findPackage(t, p, "example.com/app"); len(pkg.Funcs) != 0 {
+		t.Errorf("ax.go wasn't ignored: %+v", pkg.Funcs)
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	if !isGenerated("// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n") {
+		t.Error("protoc header not detected")
+	}
+	if isGenerated("package api\n\n// Code generated by hand. DO NOT EDIT.\n") {
+		t.Error("comment after the package clause detected as generated")
+	}
+}
+
+func TestFileConstraints(t *testing.T) {
+	p := New(models.Settings{GOOS: "linux", GOARCH: "arm64", BuildTags: []string{"integration"}})
+	tests := []struct {
+		path    string
+		content string
+		build   string // Empty when built everywhere
+		kept    bool
+	}{
+		{"user.go", "package user\n", "", true},
+		{"user_linux.go", "package user\n", "linux", true},
+		{"user_windows.go", "package user\n", "windows", false},
+		{"user_linux_amd64_test.go", "package user\n", "linux && amd64", false},
+		{"user_unix.go", "//go:build unix && !js\n\npackage user\n", "unix && !js", true},
+		{"user_darwin.go", "//go:build integration\n\npackage user\n", "integration && darwin", false},
+		{"old.go", "// +build linux darwin\n// +build arm64\n\npackage user\n", "(linux || darwin) && arm64", true},
+		{"ignored.go", "//go:build ignore\n\npackage main\n", "ignore", false},
+		{"doc.go", "package user\n\n//go:build windows\n", "", true},
+	}
+	for _, test := range tests {
+		build := ""
+		kept := true
+		if expr := fileConstraint(test.path, test.content); expr != nil {
+			build = expr.String()
+			kept = expr.Eval(p.hasTag)
+		}
+		if build != test.build || kept != test.kept {
+			t.Errorf("%s: constraint %q kept %v, want %q kept %v", test.path, build, kept, test.build, test.kept)
+		}
+	}
+}
+
+// Blocks that disagree with their declarations are reported with a code and severity that says whether save fails
+func TestVerifyDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		code     string
+		severity models.Severity
+		message  string
+		also     string // Code of an error reported after it, if any
+	}{
+		{
+			name:     "func doesn't exist",
+			source:   "/***\n-- FUNC\n@func Missing\n@desc Gone.\n*/\n",
+			code:     models.CodeNoDeclaration,
+			severity: models.SeverityError,
+			message:  "func 'Missing' is documented",
+		},
+		{
+			name:     "func name",
+			source:   "/***\n-- FUNC\n@func Start\n@desc Starts.\n*/\nfunc Stop() {}\n",
+			code:     models.CodeMismatch,
+			severity: models.SeverityWarning,
+			message:  "followed by func 'Stop'",
+			also:     models.CodeNoDeclaration,
+		},
+		{
+			name:     "receiver",
+			source:   "type Store struct{}\ntype Cache struct{}\n\n/***\n-- FUNC\n@func Save\n@rec Cache\n@desc Saves.\n*/\nfunc (s *Store) Save() {}\n",
+			code:     models.CodeMismatch,
+			severity: models.SeverityWarning,
+			message:  "receiver is 'Store'",
+		},
+		{
+			name:     "param type",
+			source:   "/***\n-- FUNC\n@func Open\n@desc Opens.\n@param name (int): File name.\n*/\nfunc Open(name string) {}\n",
+			code:     models.CodeMismatch,
+			severity: models.SeverityWarning,
+			message:  "parameter 'name' as 'int'",
+		},
+		{
+			name:     "param doesn't exist",
+			source:   "/***\n-- FUNC\n@func Open\n@desc Opens.\n@param mode (int): File mode.\n*/\nfunc Open() {}\n",
+			code:     models.CodeNoDeclaration,
+			severity: models.SeverityError,
+			message:  "parameter 'mode' which does not exist",
+		},
+		{
+			name:     "param undocumented",
+			source:   "/***\n-- FUNC\n@func Open\n@desc Opens.\n@param name (string): File name.\n*/\nfunc Open(name string, mode int) {}\n",
+			code:     models.CodeUndocumented,
+			severity: models.SeverityInfo,
+			message:  "parameter 'mode'",
+		},
+		{
+			name:     "return count",
+			source:   "/***\n-- FUNC\n@func Open\n@desc Opens.\n@return (error): Why it failed.\n*/\nfunc Open() {}\n",
+			code:     models.CodeMismatch,
+			severity: models.SeverityWarning,
+			message:  "1 return value(s) but the declaration has 0",
+		},
+		{
+			name:     "type doesn't exist",
+			source:   "/***\n-- TYPE\n@type Missing\n@desc Gone.\n*/\n",
+			code:     models.CodeNoDeclaration,
+			severity: models.SeverityError,
+			message:  "type 'Missing' is documented",
+		},
+		{
+			name:     "field type",
+			source:   "/***\n-- TYPE\n@type User\n@desc A user.\n@field ID (string): Primary key.\n*/\ntype User struct {\n\tID int\n}\n",
+			code:     models.CodeMismatch,
+			severity: models.SeverityWarning,
+			message:  "field 'ID' as 'string'",
+		},
+		{
+			name:     "field doesn't exist",
+			source:   "/***\n-- TYPE\n@type User\n@desc A user.\n@field ID (int): Primary key.\n@field Name (string): Login name.\n*/\ntype User struct {\n\tID int\n}\n",
+			code:     models.CodeNoDeclaration,
+			severity: models.SeverityError,
+			message:  "field 'Name' which does not exist",
+		},
+		{
+			name:     "var doesn't exist",
+			source:   "/***\n-- VAR\n@var Missing\n@desc Gone.\n*/\n",
+			code:     models.CodeNoDeclaration,
+			severity: models.SeverityError,
+			message:  "var 'Missing' is documented",
+		},
+		{
+			name:     "var type",
+			source:   "/***\n-- VAR\n@var Limit\n@type string\n@desc Most users.\n*/\nvar Limit int\n",
+			code:     models.CodeMismatch,
+			severity: models.SeverityWarning,
+			message:  "type 'string' but the declaration has 'int'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := parseProject(t, models.Settings{}, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.22\n",
+				"app.go": "package app\n\n/***\n-- PKG\n@pkg app\n@desc App.\n*/\n\n" + test.source,
+			})
+
+			want := 1
+			if test.also != "" {
+				want = 2
+			}
+			if len(p.Diagnostics) != want {
+				t.Fatalf("diagnostics = %v, want %d", p.Diagnostics, want)
+			}
+			got := p.Diagnostics[0]
+			if got.Code != test.code || got.Severity != test.severity || !strings.Contains(got.Message, test.message) {
+				t.Errorf("diagnostic = %v, want %s %s containing %q", got, test.severity, test.code, test.message)
+			}
+			if test.also != "" && (p.Diagnostics[1].Code != test.also || p.Diagnostics[1].Severity != models.SeverityError) {
+				t.Errorf("diagnostic = %v, want a %s error", p.Diagnostics[1], test.also)
+			}
+			// Only a documented item that doesn't exist fails the save
+			if models.HasErrors(p.Diagnostics) != (test.severity == models.SeverityError || test.also != "") {
+				t.Errorf("HasErrors = %v for %v", models.HasErrors(p.Diagnostics), p.Diagnostics)
+			}
+		})
+	}
+}
+
+// The receiver keeps its '*', so pointer and value methods render with the receiver they're declared on
+func TestReceiverType(t *testing.T) {
+	p := parseProject(t, models.Settings{}, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"app.go": "package app\n\n/***\n-- PKG\n@pkg app\n@desc App.\n*/\n\ntype Store struct{}\n\n" +
+			"/***\n-- FUNC\n@func Save\n@rec Store\n@desc Saves.\n*/\nfunc (s *Store) Save() {}\n\n" +
+			"/***\n-- FUNC\n@func Len\n@desc Counts.\n*/\nfunc (s Store) Len() {}\n",
+	})
+	if len(p.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", p.Diagnostics)
+	}
+
+	pkg := findPackage(t, p, "example.com/app")
+	types := pkg.Types
+	for _, file := range pkg.Files {
+		types = append(types, file.Types...)
+	}
+	receivers := make(map[string]string)
+	for _, _type := range types {
+		for _, method := range _type.Methods {
+			receivers[method.Name] = method.Receiver
+		}
+	}
+	if want := map[string]string{"Save": "*Store", "Len": "Store"}; !reflect.DeepEqual(receivers, want) {
+		t.Errorf("receivers = %v, want %v", receivers, want)
+	}
+}
+
+// A save that takes unchanged packages from the cache has to produce exactly what a full save does
+func TestCache(t *testing.T) {
+	files := map[string]string{
+		"go.mod":         "module example.com/app\n\ngo 1.22\n",
+		"settings.json":  "{}\n",
+		"app.go":         "package app\n\n/***\n-- PKG\n@pkg app\n@desc App.\n*/\n\n/***\n-- FUNC\n@func Start\n@desc Starts.\n*/\nfunc Start() {}\n",
+		"users/users.go": "package users\n\n/***\n-- PKG\n@pkg users\n@desc Users.\n*/\n\n/***\n-- VAR\n@var Limit\n@desc Most users.\n*/\nvar Limit = 10\n",
+	}
+
+	tests := []struct {
+		name     string
+		settings models.Settings
+		edit     map[string]string // Files changed between the two saves
+		cached   int               // Files the second save takes from the cache
+	}{
+		{name: "nothing changed", cached: 2},
+		{
+			name:   "one file changed",
+			edit:   map[string]string{"app.go": "package app\n\n/***\n-- PKG\n@pkg app\n@desc Edited.\n*/\n\n/***\n-- FUNC\n@func Stop\n@desc Stops.\n*/\nfunc Stop() {}\n"},
+			cached: 1,
+		},
+		{
+			name:   "file added",
+			edit:   map[string]string{"users/admin.go": "package users\n\n/***\n-- FUNC\n@func Promote\n@desc Makes an admin.\n*/\nfunc Promote() {}\n"},
+			cached: 1,
+		},
+		{
+			name:     "settings changed",
+			settings: models.Settings{IncludePrivateVars: true},
+			cached:   0,
+		},
+		{
+			name:   "go.mod changed",
+			edit:   map[string]string{"go.mod": "module example.com/renamed\n\ngo 1.22\n"},
+			cached: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, files)
+			chdirTemp(t)
+			if first := save(root, models.Settings{}, false); len(first.Diagnostics) != 0 || first.cached != 0 {
+				t.Fatalf("first save took %d files from the cache, diagnostics = %v", first.cached, first.Diagnostics)
+			}
+
+			writeFiles(t, root, test.edit)
+			second := save(root, test.settings, false)
+			if second.cached != test.cached {
+				t.Errorf("second save took %d files from the cache, want %d", second.cached, test.cached)
+			}
+			output, err := os.ReadFile("godoc_output.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			full := save(root, test.settings, true)
+			if !reflect.DeepEqual(second.Modules, full.Modules) || !reflect.DeepEqual(second.Diagnostics, full.Diagnostics) {
+				t.Errorf("cached save differs from a full one:\n%+v\n%+v", second.Modules, full.Modules)
+			}
+			if fullOutput, err := os.ReadFile("godoc_output.json"); err != nil || string(output) != string(fullOutput) {
+				t.Errorf("godoc_output.json differs from a full save's (%v)", err)
+			}
+		})
+	}
+}
+
+// A cache written by another build of GoDoc is never used
+func TestCacheFromAnotherBuild(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"app.go": "package app\n\n/***\n-- PKG\n@pkg app\n@desc App.\n*/\n",
+	})
+	chdirTemp(t)
+	p := save(root, models.Settings{}, false)
+
+	// Stand in for an older build by storing the key it would have computed
+	stale := newCache("key from another build")
+	stale.Files, stale.Dirs = p.loadCache(p.cacheKey()).Files, p.loadCache(p.cacheKey()).Dirs
+	if err := stale.save(); err != nil {
+		t.Fatal(err)
+	}
+	if p := save(root, models.Settings{}, false); p.cached != 0 {
+		t.Errorf("save took %d files from another build's cache", p.cached)
+	}
+	if buildID() == "" {
+		t.Error("the build has no ID to key the cache with")
+	}
+}
+
+// Types from different packages of the same name are told apart by import path, however they are imported
+func TestImplementersAcrossSameNamedPackages(t *testing.T) {
+	p := parseProject(t, models.Settings{IncludeDocComments: true}, map[string]string{
+		"go.mod":          "module example.com/app\n\ngo 1.22\n",
+		"a/model/user.go": "// Package model holds accounts.\npackage model\n\n// User is an account.\ntype User struct{}\n",
+		"b/model/user.go": "// Package model holds other accounts.\npackage model\n\n// User is another account.\ntype User struct{}\n",
+		"a/store/store.go": "// Package store saves things.\npackage store\n\nimport \"example.com/app/a/model\"\n\n" +
+			"// Item is something kept.\ntype Item struct{}\n\n" +
+			"// Saver saves users.\ntype Saver interface {\n\tSave(u model.User) error\n}\n\n" +
+			"// Keeper keeps items.\ntype Keeper interface {\n\tKeep(item Item)\n}\n",
+		"b/store/store.go": "// Package store keeps other things.\npackage store\n\n// Item is something else kept.\ntype Item struct{}\n\n" +
+			"// Shelf keeps its own items.\ntype Shelf struct{}\n\n// Keep keeps an item.\nfunc (s Shelf) Keep(item Item) {}\n",
+		"impl/impl.go": "// Package impl saves users.\npackage impl\n\nimport (\n\t\"example.com/app/a/model\"\n\tam \"example.com/app/a/model\"\n\tother \"example.com/app/b/model\"\n\t\"example.com/app/a/store\"\n)\n\n" +
+			"// Good saves users.\ntype Good struct{}\n\n// Save saves a user.\nfunc (g Good) Save(u model.User) error { return nil }\n\n" +
+			"// Aliased saves users too.\ntype Aliased struct{}\n\n// Save saves a user.\nfunc (a *Aliased) Save(u am.User) error { return nil }\n\n" +
+			"// Wrong saves the other package's users.\ntype Wrong struct{}\n\n// Save saves a user.\nfunc (w Wrong) Save(u other.User) error { return nil }\n\n" +
+			"// Box keeps items.\ntype Box struct{}\n\n// Keep keeps an item.\nfunc (b Box) Keep(item store.Item) {}\n",
+	})
+	if models.HasErrors(p.Diagnostics) {
+		t.Fatalf("unexpected errors: %v", p.Diagnostics)
+	}
+
+	implementers := make(map[string]string)
+	for _, iface := range findPackage(t, p, "example.com/app/a/store").Interfaces {
+		implementers[iface.Name] = strings.Join(iface.Implementers, ",")
+	}
+	want := map[string]string{"Saver": "*impl.Aliased,impl.Good", "Keeper": "impl.Box"}
+	if !reflect.DeepEqual(implementers, want) {
+		t.Errorf("implementers = %v, want %v", implementers, want)
+	}
+}
+
+// Blocks without a name take it from the declaration they sit above
+func TestAttachedDecl(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string // Names of the funcs and vars documented, with their descriptions
+		code   string   // Code of the one diagnostic expected, if any
+	}{
+		{
+			name:   "directly above",
+			source: "/***\n-- FUNC\n@desc Starts.\n*/\nfunc Start() {}\n",
+			want:   []string{"Start: Starts."},
+		},
+		{
+			name:   "blank line between",
+			source: "/***\n-- FUNC\n@desc Starts.\n*/\n\nfunc Start() {}\n",
+			want:   []string{"Start: Starts."},
+		},
+		{
+			name:   "comment between",
+			source: "/***\n-- FUNC\n@desc Starts.\n*/\n\n// Start is called once.\nfunc Start() {}\n",
+			want:   []string{"Start: Starts."},
+		},
+		{
+			name:   "other kind between",
+			source: "/***\n-- FUNC\n@desc Starts.\n*/\n\nvar started bool\n\nfunc Start() {}\n",
+			want:   []string{"Start: Starts."},
+		},
+		{
+			name:   "same kind between",
+			source: "/***\n-- FUNC\n@desc Starts.\n*/\n\n/***\n-- FUNC\n@desc Stops.\n*/\nfunc Stop() {}\n",
+			want:   []string{"Stop: Stops."},
+			code:   models.CodeMissingName,
+		},
+		{
+			name:   "var group",
+			source: "var (\n\tMin = 1\n\n\t/***\n\t-- VAR\n\t@desc Most users.\n\t*/\n\tMax = 10\n)\n",
+			want:   []string{"Max: Most users."},
+		},
+		{
+			name:   "const group",
+			source: "const (\n\t/***\n\t-- VAR\n\t@desc Least users.\n\t*/\n\tMin = 1\n\tMax = 10\n)\n",
+			want:   []string{"Min: Least users."},
+		},
+		{
+			name:   "nothing below",
+			source: "func Start() {}\n\n/***\n-- FUNC\n@desc Starts.\n*/\n",
+			code:   models.CodeMissingName,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := parseProject(t, models.Settings{}, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.22\n",
+				"app.go": "package app\n\n/***\n-- PKG\n@pkg app\n@desc App.\n*/\n\n" + test.source,
+			})
+
+			if test.code == "" && len(p.Diagnostics) != 0 {
+				t.Fatalf("unexpected diagnostics: %v", p.Diagnostics)
+			}
+			if test.code != "" && (len(p.Diagnostics) != 1 || p.Diagnostics[0].Code != test.code) {
+				t.Fatalf("diagnostics = %v, want one %s", p.Diagnostics, test.code)
+			}
+
+			pkg := findPackage(t, p, "example.com/app")
+			funcs, vars := pkg.Funcs, pkg.Vars
+			for _, file := range pkg.Files {
+				funcs, vars = append(funcs, file.Funcs...), append(vars, file.Vars...)
+			}
+			var got []string
+			for _, function := range funcs {
+				got = append(got, function.Name+": "+function.Desc)
+			}
+			for _, variable := range vars {
+				got = append(got, variable.Name+": "+variable.Desc)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("documented = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// pkgSource is a file of a package with a PKG block, so the package is documented
+func pkgSource(name string) string {
+	return "package " + name + "\n\n/***\n-- PKG\n@pkg " + name + "\n@desc The " + name + " package.\n*/\n"
+}
+
+// modulePackages lists each module's path with the import paths of its packages, as 'module: pkg, pkg'
+func modulePackages(p *Parser) []string {
+	var got []string
+	for _, mod := range p.Modules {
+		var importPaths []string
+		for _, pkg := range mod.Packages {
+			importPaths = append(importPaths, pkg.ImportPath)
+		}
+		got = append(got, mod.Path+": "+strings.Join(importPaths, ", "))
+	}
+	return got
+}
+
+func TestModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		project string   // ProjectPath, relative to where the files are written
+		want    []string // From modulePackages
+		code    string   // Code of the one diagnostic expected, if any
+		file    string   // File the diagnostic is reported against
+	}{
+		{
+			name: "workspace and nested module",
+			files: map[string]string{
+				"go.work":              "go 1.22\n\nuse (\n\t./app\n\t./lib // Outside the project\n)\n",
+				"app/go.mod":           "module example.com/app\n\ngo 1.22\n",
+				"app/main.go":          pkgSource("main"),
+				"app/users/users.go":   pkgSource("users"),
+				"app/tools/go.mod":     "module example.com/tools\n\ngo 1.22\n",
+				"app/tools/gen/gen.go": pkgSource("gen"),
+				"lib/go.mod":           "module example.com/lib\n\ngo 1.21\n",
+				"lib/lib.go":           pkgSource("lib"),
+			},
+			project: "app",
+			want: []string{
+				"example.com/app: example.com/app, example.com/app/users",
+				"example.com/tools: example.com/tools/gen",
+				"example.com/lib: example.com/lib",
+			},
+		},
+		{
+			name: "files outside any module",
+			files: map[string]string{
+				"scripts/run.go":     pkgSource("main"),
+				"svc/go.mod":         "module example.com/svc\n\ngo 1.22\n",
+				"svc/api/api.go":     pkgSource("api"),
+				"scripts/db/seed.go": pkgSource("db"),
+			},
+			want: []string{
+				"example.com/svc: example.com/svc/api",
+				": scripts, scripts/db",
+			},
+		},
+		{
+			name: "go.mod without a module directive",
+			files: map[string]string{
+				"go.mod":        "module example.com/app\n\ngo 1.22\n",
+				"app.go":        pkgSource("app"),
+				"broken/go.mod": "go 1.22\n",
+				"broken/b.go":   pkgSource("b"),
+			},
+			want: []string{"example.com/app: example.com/app, example.com/app/broken"},
+			code: models.CodeModule,
+			file: filepath.Join("broken", "go.mod"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, test.files)
+			chdirTemp(t)
+			project := filepath.Join(root, test.project)
+			p := save(project, models.Settings{}, true)
+
+			if test.code == "" && len(p.Diagnostics) != 0 {
+				t.Fatalf("unexpected diagnostics: %v", p.Diagnostics)
+			}
+			if test.code != "" {
+				if len(p.Diagnostics) != 1 || p.Diagnostics[0].Code != test.code || p.Diagnostics[0].Severity != models.SeverityError {
+					t.Fatalf("diagnostics = %v, want one %s error", p.Diagnostics, test.code)
+				}
+				if want := filepath.Join(project, test.file); p.Diagnostics[0].Pos.File != want {
+					t.Errorf("diagnostic is at '%s', want '%s'", p.Diagnostics[0].Pos.File, want)
+				}
+			}
+			if got := modulePackages(p); !slices.Equal(got, test.want) {
+				t.Errorf("modules = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Diagnostics point at the exact byte of the source, however the block is indented or decorated
+func TestDiagnosticPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		block string // Block with one bad '(type)', which the diagnostic has to point at
+	}{
+		{
+			name:  "tabs",
+			block: "/***\n\t-- FUNC\n\t@func Open\n\t@param name (string]): File name.\n*/\n",
+		},
+		{
+			name:  "tab and space mix",
+			block: "/***\n \t-- FUNC\n \t@func Open\n\t \t@param name (string]): File name.\n*/\n",
+		},
+		{
+			name:  "javadoc",
+			block: "/***\n * -- FUNC\n * @func Open\n * @param name (string]): File name.\n */\n",
+		},
+		{
+			name:  "javadoc with tabs and wide gutter",
+			block: "\t/***\n\t *   -- FUNC\n\t *   @func Open\n\t *   @param name (string]): File name.\n\t */\n",
+		},
+		{
+			name:  "after a wrapped description",
+			block: "/***\n-- FUNC\n@func Open\n@desc Opens a file,\n  creating it first\n  if it is missing.\n@param name (string]): File name.\n*/\n",
+		},
+		{
+			name:  "javadoc after a wrapped description",
+			block: "/**\n * -- FUNC\n * @func Open\n * @desc Opens a file,\n *   creating it first\n *\n *   if it is missing.\n * @param name (string]): File name.\n */\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source := "package app\n\n/***\n-- PKG\n@pkg app\n@desc App.\n*/\n\n" + test.block + "func Open(name string) {}\n"
+			p := parseProject(t, models.Settings{}, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.22\n",
+				"app.go": source,
+			})
+
+			// Where '(string])' is in the source, counting columns in bytes as go/token does
+			offset := strings.Index(source, "(string])")
+			line := 1 + strings.Count(source[:offset], "\n")
+			column := offset - strings.LastIndex(source[:offset], "\n")
+
+			var found bool
+			for _, diag := range p.Diagnostics {
+				if diag.Code != models.CodeMalformedType {
+					continue
+				}
+				found = true
+				if diag.Pos.Line != line || diag.Pos.Column != column || diag.Pos.Offset != offset {
+					t.Errorf("diagnostic at %d:%d (offset %d), want %d:%d (offset %d)", diag.Pos.Line, diag.Pos.Column, diag.Pos.Offset, line, column, offset)
+				}
+			}
+			if !found {
+				t.Errorf("diagnostics = %v, want one for the bad type", p.Diagnostics)
+			}
+		})
+	}
+}
+
+// Files are read on a pool of workers, so however the work is scheduled the save has to come out byte for byte the same
+func TestDeterministicSave(t *testing.T) {
+	settings := models.Settings{IncludeTests: true, IncludePrivateFuncs: true, IncludePrivateVars: true, IncludePrivateTypes: true}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	fixture, err := filepath.Abs(filepath.Join("..", "..", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdirTemp(t)
+
+	var want []byte
+	var wantDiagnostics []models.Diagnostic
+	for _, workers := range []int{1, 2, 4, 16, 16} {
+		runtime.GOMAXPROCS(workers)
+		p := save(fixture, settings, true)
+		output, err := os.ReadFile("godoc_output.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == nil {
+			want, wantDiagnostics = output, p.Diagnostics
+			continue
+		}
+		if string(output) != string(want) {
+			t.Errorf("output with %d workers differs from the output with 1", workers)
+		}
+		if !reflect.DeepEqual(p.Diagnostics, wantDiagnostics) {
+			t.Errorf("diagnostics with %d workers = %v, want %v", workers, p.Diagnostics, wantDiagnostics)
+		}
+	}
+}
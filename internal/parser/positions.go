@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"go/token"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// lineStarts records the offset each line of src begins at
+func lineStarts(src string) []int {
+	starts := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// declPosition converts a position from the Go AST
+func (p *Parser) declPosition(pos token.Pos) models.Position {
+	if !pos.IsValid() {
+		return models.Position{}
+	}
+	position := p.fset.Position(pos)
+	return models.Position{
+		File:   position.Filename,
+		Offset: position.Offset,
+		Line:   position.Line,
+		Column: position.Column,
+	}
+}
+
+// extractCommentTags reads the tags of a block (everything after its header line),
+// placing each tag at its position in the source file
+func extractCommentTags(comment models.Comment) ([]models.Tag, error) {
+	text := strings.Join(comment.Text[1:], "\n") // All of the comment except the header line
+	tags, err := extractTagData(text)
+
+	for i := range tags {
+		// Tags come back positioned within text, which is made of the trimmed lines of the block
+		offset := tags[i].Pos.Offset
+		line := 1 + strings.Count(text[:offset], "\n")
+		column := offset - (strings.LastIndex(text[:offset], "\n") + 1)
+		if line < len(comment.Lines) {
+			start := comment.Lines[line]
+			tags[i].Pos = models.Position{
+				File:   start.File,
+				Offset: start.Offset + column,
+				Line:   start.Line,
+				Column: start.Column + column,
+			}
+		}
+	}
+	return tags, err
+}
+
+// contentPosition is where a tag's content starts, one space after its name.
+// It is only exact on the tag's first line, which is where types and names are written.
+func contentPosition(tag models.Tag) models.Position {
+	return shiftPosition(tag.Pos, len("@")+len(tag.Name)+len(" "))
+}
@@ -0,0 +1,333 @@
+package parser
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// sourceFile is everything read out of a single .go file.
+// Files are read concurrently, so reading one only ever writes to its own sourceFile.
+type sourceFile struct {
+	path        string
+	importPath  string
+	included    bool // Brought back by a '!' pattern in .godocignore, so kept even if generated
+	content     string
+	hash        string // SHA-256 of content, empty if the file couldn't be read
+	lines       []int  // Line start offsets
+	ast         *ast.File
+	comments    []models.Comment
+	diagnostics []models.Diagnostic
+}
+
+// sourceDir is a directory's files, which make up a single package
+type sourceDir struct {
+	path      string
+	files     []*sourceFile
+	unchanged bool // Same files with the same content as the last save
+}
+
+// groupByDir groups files by directory, keeping directories in the order their first file was found
+func groupByDir(files []*sourceFile) []*sourceDir {
+	var dirs []*sourceDir
+	byPath := make(map[string]*sourceDir)
+	for _, f := range files {
+		path := filepath.Dir(f.path)
+		dir, ok := byPath[path]
+		if !ok {
+			dir = &sourceDir{path: path}
+			byPath[path] = dir
+			dirs = append(dirs, dir)
+		}
+		dir.files = append(dir.files, f)
+	}
+	return dirs
+}
+
+// forEachFile runs fn over files on a bounded pool of workers. Each result stays in its own sourceFile, so callers see them in the order given.
+func forEachFile(files []*sourceFile, fn func(f *sourceFile)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	jobs := make(chan *sourceFile)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				fn(f)
+			}
+		}()
+	}
+	for _, f := range files {
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// loadSourceFile reads a file's content and hashes it
+func loadSourceFile(f *sourceFile) {
+	// Open the file
+	file, err := os.Open(f.path)
+	if err != nil {
+		f.report(err, models.Position{File: f.path})
+		return
+	}
+	defer file.Close()
+
+	// Read the content of the file
+	content, err := readFile(file)
+	if err != nil {
+		f.report(err, models.Position{File: f.path})
+		return
+	}
+	sum := sha256.Sum256([]byte(content))
+	f.content = content
+	f.hash = hex.EncodeToString(sum[:])
+}
+
+// dropGenerated leaves out files carrying the standard '// Code generated ... DO NOT EDIT.' header,
+// unless .godocignore explicitly includes them
+func dropGenerated(files []*sourceFile) []*sourceFile {
+	var kept []*sourceFile
+	for _, f := range files {
+		if !f.included && isGenerated(f.content) {
+			log.Printf("Skipping generated file '%s'\n", f.path)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// isGenerated reports whether source has a generated-code comment before its package clause
+func isGenerated(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+		if strings.HasPrefix(line, "// Code generated ") && strings.HasSuffix(line, " DO NOT EDIT.") {
+			return true
+		}
+	}
+	return false
+}
+
+// readSourceFile parses the Go source of a loaded file and extracts its GoDoc blocks.
+// If the file hasn't changed since the last save, its blocks are taken from the cache and only the Go source is parsed.
+func (p *Parser) readSourceFile(f *sourceFile, cached cachedFile, hit bool) {
+	if f.hash == "" {
+		return
+	}
+	f.lines = lineStarts(f.content)
+	err := p.parseGoFile(f)
+	if hit {
+		f.comments = cached.Comments
+		f.diagnostics = append(f.diagnostics, cached.Diagnostics...)
+		return
+	}
+	if err != nil {
+		f.report(err, models.Position{File: f.path})
+	}
+
+	pkgName := extractPkgName(f.content)
+
+	// Now proceed to extract comments
+	l := newLexer(f.content)
+	for !l.isAtEnd() {
+		if l.isGoDocComment() {
+			comment := f.extractBlockComment(l, pkgName)
+			if !isEmptyComment(comment) {
+				f.comments = append(f.comments, comment)
+			}
+		} else if l.isDoxygenComment() && p.isCommentAt(f, l.position) {
+			comment := p.extractDoxygenComment(f, l, pkgName)
+			if !isEmptyComment(comment) {
+				f.comments = append(f.comments, comment)
+			}
+		} else {
+			l.readChar()
+		}
+	}
+}
+
+func (f *sourceFile) extractBlockComment(l *lexer, pkgName string) models.Comment {
+	pos := f.positionAt(l.position)
+	l.advanceBy(4) // Skip /***
+	lines, linePositions := f.readBlockLines(l)
+
+	if len(lines) == 0 {
+		f.report(models.Warningf(models.CodeEmptyBlock, "GoDoc block has no lines"), pos)
+		return models.Comment{}
+	}
+
+	return models.Comment{
+		File:       f.path,
+		Package:    pkgName,
+		ImportPath: f.importPath,
+		Pos:        pos,
+		Text:       lines,
+		Lines:      linePositions,
+	}
+}
+
+// readBlockLines reads the lines of a block comment up to its closing */, starting just after the opening /***
+func (f *sourceFile) readBlockLines(l *lexer) ([]string, []models.Position) {
+	var lines []string
+	var linePositions []models.Position
+
+	for !l.isAtEnd() {
+		lineStart := f.positionAt(l.position)
+
+		// Read each line whole, indentation included, so descriptions can keep indented code and nested lists
+		var sb strings.Builder
+		for l.ch != '\n' && l.ch != 0 {
+			sb.WriteByte(l.ch)
+			l.readChar()
+		}
+		line := strings.TrimRight(sb.String(), " \t\r")
+		l.readChar() // Move to the next line
+
+		// Check for the end of the block comment */, which may follow text on the same line
+		if end := strings.Index(line, "*/"); end >= 0 {
+			if text := strings.TrimRight(line[:end], " \t"); strings.TrimSpace(text) != "" {
+				lines = append(lines, text)
+				linePositions = append(linePositions, lineStart)
+			}
+			break
+		}
+
+		lines = append(lines, line)
+		linePositions = append(linePositions, lineStart)
+	}
+	return dedentLines(lines, linePositions)
+}
+
+// dedentLines removes the indentation every line of a block shares, along with blank lines at either end.
+// The first line is whatever follows /*** and is trimmed on its own. Positions move to where each line's text now starts.
+func dedentLines(lines []string, positions []models.Position) ([]string, []models.Position) {
+	sameLine := false // Text right after /*** on the same line
+	if len(lines) > 0 {
+		trimmed := strings.TrimLeft(lines[0], " \t")
+		positions[0] = shiftPosition(positions[0], len(lines[0])-len(trimmed))
+		lines[0] = trimmed
+		sameLine = trimmed != ""
+	}
+
+	// Javadoc-style blocks start every line with '*', which is decoration rather than text
+	if decorated(lines, sameLine) {
+		for i, line := range lines {
+			if (i == 0 && sameLine) || line == "" {
+				continue
+			}
+			text := strings.TrimPrefix(strings.TrimLeft(line, " \t")[1:], " ")
+			positions[i] = shiftPosition(positions[i], len(line)-len(text))
+			lines[i] = text
+		}
+	}
+
+	// Blank lines at either end don't belong to anything
+	for len(lines) > 0 && lines[0] == "" {
+		lines, positions = lines[1:], positions[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines, positions = lines[:len(lines)-1], positions[:len(positions)-1]
+	}
+
+	// The indentation shared by every non-blank line after the first
+	prefix, found := "", false
+	for i, line := range lines {
+		if (i == 0 && sameLine) || line == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			prefix, found = indent, true
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	for i, line := range lines {
+		if !(i == 0 && sameLine) && strings.HasPrefix(line, prefix) {
+			lines[i] = line[len(prefix):]
+			positions[i] = shiftPosition(positions[i], len(prefix))
+		}
+	}
+	return lines, positions
+}
+
+// decorated reports whether every line of a block, besides text on the /*** line, starts with '*'
+func decorated(lines []string, sameLine bool) bool {
+	found := false
+	for i, line := range lines {
+		if (i == 0 && sameLine) || line == "" {
+			continue
+		}
+		if !strings.HasPrefix(strings.TrimLeft(line, " \t"), "*") {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func shiftPosition(pos models.Position, n int) models.Position {
+	pos.Offset += n
+	pos.Column += n
+	return pos
+}
+
+// positionAt converts a byte offset in the file into a line and column
+func (f *sourceFile) positionAt(offset int) models.Position {
+	line := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset })
+	return models.Position{
+		File:   f.path,
+		Offset: offset,
+		Line:   line,
+		Column: offset - f.lines[line-1] + 1,
+	}
+}
+
+func (f *sourceFile) report(err error, pos models.Position) {
+	f.diagnostics = append(f.diagnostics, diagnosticFor(err, pos))
+}
+
+func readFile(file *os.File) (string, error) {
+	var sb strings.Builder
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		sb.WriteString(scanner.Text() + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func extractPkgName(src string) string {
+	lines := strings.Split(src, "\n")
+	for _, line := range lines {
+		// Check for the line that starts with "package "
+		if strings.HasPrefix(strings.TrimSpace(line), "package ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "package "))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"strings"
+)
+
+// formatText turns the raw lines of a tag's content into structured text: paragraphs are separated by a blank line,
+// list items sit on their own lines (nested ones indented) and code is kept in fenced blocks.
+// Lines wrapped within a paragraph or list item are joined back together.
+func formatText(content string) string {
+	lines := strings.Split(content, "\n")
+	var blocks []string
+	var block []string // Lines of the paragraph or list being read
+	kind := ""         // "paragraph", "list", or "" between blocks
+	listIndent := 0    // Indentation of the list's first item
+
+	flush := func() {
+		switch kind {
+		case "paragraph":
+			blocks = append(blocks, strings.Join(block, " "))
+		case "list":
+			blocks = append(blocks, strings.Join(block, "\n"))
+		}
+		block, kind = nil, ""
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		indent := indentWidth(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			// Fenced code is kept exactly as written, up to its closing fence
+			flush()
+			code := []string{trimmed}
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+					break
+				}
+				code = append(code, trimIndent(lines[i], indent))
+			}
+			code = append(code, "```") // Closed even if the fence was left open, so it can't swallow the rest of the docs
+			blocks = append(blocks, strings.Join(code, "\n"))
+		case trimmed == "":
+			flush()
+		case isListItem(trimmed):
+			if kind != "list" {
+				flush()
+				kind = "list"
+				listIndent = indent
+			}
+			nested := indent - listIndent
+			if nested < 0 {
+				nested = 0
+			}
+			block = append(block, strings.Repeat(" ", nested)+trimmed)
+		case kind != "":
+			// A wrapped line carries on the paragraph or list item above it
+			block[len(block)-1] += " " + trimmed
+		case indent >= 4:
+			// Indented code, which can't interrupt a paragraph
+			var code []string
+			for ; i < len(lines); i++ {
+				if strings.TrimSpace(lines[i]) != "" && indentWidth(lines[i]) < 4 {
+					break
+				}
+				code = append(code, trimIndent(lines[i], 4))
+			}
+			i-- // The line that ended the code belongs to the next block
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, "```\n"+strings.Join(code, "\n")+"\n```")
+		default:
+			kind = "paragraph"
+			block = append(block, trimmed)
+		}
+	}
+	flush()
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// isListItem reports whether a trimmed line starts with a bullet (-, * or +) or a number (1. or 1))
+func isListItem(line string) bool {
+	if len(line) >= 2 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return true
+	}
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	return digits > 0 && digits+1 < len(line) && (line[digits] == '.' || line[digits] == ')') && line[digits+1] == ' '
+}
+
+// indentWidth measures a line's indentation, counting a tab as 4 spaces
+func indentWidth(line string) int {
+	width := 0
+	for _, ch := range line {
+		if ch == ' ' {
+			width++
+		} else if ch == '\t' {
+			width += 4
+		} else {
+			break
+		}
+	}
+	return width
+}
+
+// trimIndent removes up to width columns of indentation from a line
+func trimIndent(line string, width int) string {
+	removed := 0
+	for removed < width && len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+		if line[0] == '\t' {
+			removed += 4
+		} else {
+			removed++
+		}
+		line = line[1:]
+	}
+	return line
+}
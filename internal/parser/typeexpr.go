@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/types"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// readParenthesized reads from the '(' at l.ch up to the ')' that balances it, and returns the text in between.
+// Brackets and braces must balance too, and quoted text (such as struct tags) is read as-is,
+// so types like 'func(int) error' and 'map[string][]func()' are read whole.
+func readParenthesized(l *lexer) (string, error) {
+	l.readChar() // Consume '('
+	start := l.position
+	var closers []byte // Closing brackets still expected, innermost last
+
+	for {
+		switch l.ch {
+		case 0:
+			return "", models.Errorf(models.CodeMalformedType, "'(%s' is missing its closing ')'", l.src[start:])
+		case '(':
+			closers = append(closers, ')')
+		case '[':
+			closers = append(closers, ']')
+		case '{':
+			closers = append(closers, '}')
+		case ')', ']', '}':
+			if len(closers) == 0 && l.ch == ')' {
+				text := l.src[start:l.position]
+				l.readChar() // Consume ')'
+				return text, nil
+			}
+			if len(closers) == 0 || closers[len(closers)-1] != l.ch {
+				return "", models.Errorf(models.CodeMalformedType, "unbalanced '%c' in '(%s'", l.ch, l.src[start:l.position+1])
+			}
+			closers = closers[:len(closers)-1]
+		case '"', '`':
+			// Skip to the closing quote, '"' strings may escape it
+			quote := l.ch
+			for l.readChar(); l.ch != 0 && l.ch != quote; l.readChar() {
+				if quote == '"' && l.ch == '\\' {
+					l.readChar()
+				}
+			}
+			if l.ch == 0 {
+				return "", models.Errorf(models.CodeMalformedType, "unclosed string in '(%s'", l.src[start:])
+			}
+		}
+		l.readChar()
+	}
+}
+
+// parseTypeAnnotation checks that text is a Go type expression and returns it formatted the way gofmt would print it.
+// A leading '...' is allowed for variadic parameters.
+func parseTypeAnnotation(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	variadic := strings.HasPrefix(text, "...")
+	if variadic {
+		text = strings.TrimSpace(text[3:])
+	}
+
+	expr, err := goparser.ParseExpr(text)
+	if err != nil {
+		return "", models.Errorf(models.CodeMalformedType, "'%s' is not a valid Go type: %v", text, parseErrorMessage(err)).WithFix("write the type as it appears in Go source, such as '(map[string]int)'")
+	}
+	if !isTypeExpr(expr) {
+		return "", models.Errorf(models.CodeMalformedType, "'%s' is an expression, not a Go type", text).WithFix("write the type as it appears in Go source, such as '(map[string]int)'")
+	}
+
+	normalized := types.ExprString(expr)
+	if variadic {
+		normalized = "..." + normalized
+	}
+	return normalized, nil
+}
+
+// parseErrorMessage drops the '1:5:' position go/parser puts in front of its errors, which is meaningless for a tag
+func parseErrorMessage(err error) string {
+	message := err.Error()
+	if i := strings.Index(message, ": "); i >= 0 && strings.Count(message[:i], ":") == 1 {
+		return message[i+2:]
+	}
+	return message
+}
+
+// isTypeExpr reports whether an expression can only be read as a type, such as 'int', '*pkg.T', '[]byte' or 'List[T]'
+func isTypeExpr(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		_, ok := e.X.(*ast.Ident)
+		return ok
+	case *ast.StarExpr:
+		return isTypeExpr(e.X)
+	case *ast.ParenExpr:
+		return isTypeExpr(e.X)
+	case *ast.ArrayType:
+		return isTypeExpr(e.Elt)
+	case *ast.MapType:
+		return isTypeExpr(e.Key) && isTypeExpr(e.Value)
+	case *ast.ChanType:
+		return isTypeExpr(e.Value)
+	case *ast.FuncType, *ast.InterfaceType, *ast.StructType:
+		return true
+	case *ast.IndexExpr:
+		// Instantiated generic type
+		return isTypeExpr(e.X) && isTypeExpr(e.Index)
+	case *ast.IndexListExpr:
+		for _, index := range e.Indices {
+			if !isTypeExpr(index) {
+				return false
+			}
+		}
+		return isTypeExpr(e.X)
+	}
+	return false
+}
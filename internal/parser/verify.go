@@ -0,0 +1,394 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// parseGoFile loads the Go source of a file so GoDoc blocks can be checked against the real declarations
+func (p *Parser) parseGoFile(f *sourceFile) error {
+	file, err := goparser.ParseFile(p.fset, f.path, f.content, goparser.ParseComments)
+	f.ast = file
+	if err != nil {
+		return models.Warningf(models.CodeGoSyntax, "could not fully parse Go source, declarations may not be verified: %v", err)
+	}
+	return nil
+}
+
+// packageFiles returns the parsed Go files of the package filePath belongs to, starting with filePath itself
+// and followed by the rest sorted by path, so lookups by name always find the same declaration
+func (p *Parser) packageFiles(filePath string) []*ast.File {
+	file, ok := p.files[filePath]
+	if !ok {
+		// The file couldn't be parsed at all, so its package is unknown and any package in the directory will do
+		return p.dirFiles(filepath.Dir(filePath), "")
+	}
+	files := []*ast.File{file}
+	for _, other := range p.dirFiles(filepath.Dir(filePath), file.Name.Name) {
+		if other != file {
+			files = append(files, other)
+		}
+	}
+	return files
+}
+
+// resolveFunc checks a FUNC block against the declaration it documents and fills in whatever the block left out.
+// The declaration is authoritative, so mismatching types are reported and then replaced.
+func (p *Parser) resolveFunc(function *models.Func, comment models.Comment, comments []models.Comment) {
+	where := comment.Pos
+	files := p.packageFiles(comment.File)
+	if len(files) == 0 {
+		return
+	}
+
+	var decl *ast.FuncDecl
+	if attached, ok := p.attachedDecl(comment, comments).(*ast.FuncDecl); ok {
+		decl = attached
+	}
+	if decl == nil || (function.Name != "" && decl.Name.Name != function.Name) {
+		// The block isn't directly above its func, fall back to looking it up by name
+		if byName := findFuncDecl(files, function.Name, function.Receiver); byName != nil {
+			decl = byName
+		} else if decl != nil {
+			p.report(models.Warningf(models.CodeMismatch, "block for func '%s' is followed by func '%s'", function.Name, decl.Name.Name), where)
+			decl = nil
+		}
+	}
+	if decl == nil {
+		if function.Name != "" {
+			p.report(models.Errorf(models.CodeNoDeclaration, "func '%s' is documented but no matching declaration exists", function.Name).WithFix("remove the block or correct the name it documents"), where)
+		}
+		return
+	}
+	function.Name = decl.Name.Name
+
+	// Receiver
+	actualRec := receiverName(decl)
+	if function.Receiver != "" && trimPointer(function.Receiver) != actualRec {
+		if actualRec == "" {
+			p.report(models.Warningf(models.CodeMismatch, "func '%s' documents receiver '%s' but the declaration has no receiver", function.Name, function.Receiver), where)
+		} else {
+			p.report(models.Warningf(models.CodeMismatch, "func '%s' documents receiver '%s' but the declaration's receiver is '%s'", function.Name, function.Receiver, actualRec), where)
+		}
+	}
+	function.Receiver = receiverType(decl)
+
+	p.resolveSignature(function, decl.Type, where, fmt.Sprintf("func '%s'", function.Name))
+}
+
+// resolveSignature checks a func's or method's documented params and return values against its declared type,
+// taking the types from the declaration. owner names the func in warnings.
+func (p *Parser) resolveSignature(function *models.Func, fnType *ast.FuncType, where models.Position, owner string) {
+	// Parameters
+	function.Params = p.mergeVars(function.Params, p.fieldVars(fnType.Params), where, owner, "parameter")
+
+	// Return values are matched by position since they are usually unnamed
+	results := p.fieldVars(fnType.Results)
+	if len(function.Returns) > len(results) {
+		p.report(models.Warningf(models.CodeMismatch, "%s documents %d return value(s) but the declaration has %d", owner, len(function.Returns), len(results)), where)
+		function.Returns = function.Returns[:len(results)]
+	}
+	for i, result := range results {
+		if i >= len(function.Returns) {
+			function.Returns = append(function.Returns, models.ReturnResponse{Paren: result.Type})
+			continue
+		}
+		if function.Returns[i].Paren != "" && !sameType(function.Returns[i].Paren, result.Type) {
+			p.report(models.Warningf(models.CodeMismatch, "%s documents return value %d as '%s' but the declaration has '%s'", owner, i+1, function.Returns[i].Paren, result.Type).WithFix(fmt.Sprintf("change the documented type to '%s' or leave it out", result.Type)), where)
+		}
+		function.Returns[i].Paren = result.Type
+	}
+}
+
+// resolveType checks a TYPE block against its declaration and fills in the name and struct fields
+func (p *Parser) resolveType(_type *models.Type, comment models.Comment, comments []models.Comment) {
+	where := comment.Pos
+	files := p.packageFiles(comment.File)
+	if len(files) == 0 {
+		return
+	}
+
+	spec, _ := p.attachedDecl(comment, comments).(*ast.TypeSpec)
+	if spec == nil || (_type.Name != "" && spec.Name.Name != _type.Name) {
+		if byName := findTypeSpec(files, _type.Name); byName != nil {
+			spec = byName
+		} else if spec != nil {
+			p.report(models.Warningf(models.CodeMismatch, "block for type '%s' is followed by type '%s'", _type.Name, spec.Name.Name), where)
+			spec = nil
+		}
+	}
+	if spec == nil {
+		if _type.Name != "" {
+			p.report(models.Errorf(models.CodeNoDeclaration, "type '%s' is documented but no matching declaration exists", _type.Name).WithFix("remove the block or correct the name it documents"), where)
+		}
+		return
+	}
+	_type.Name = spec.Name.Name
+	_type.MethodSet = typeMethodSet(files, _type.Name, comment.ImportPath)
+
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		if len(_type.Fields) > 0 {
+			p.report(models.Warningf(models.CodeMismatch, "type '%s' documents fields but is declared as '%s'", _type.Name, types.ExprString(spec.Type)), where)
+		}
+		return
+	}
+	_type.Fields = p.mergeVars(_type.Fields, p.fieldVars(structType.Fields), where, fmt.Sprintf("type '%s'", _type.Name), "field")
+}
+
+// resolveVar checks a VAR block against its declaration and fills in the name and type
+func (p *Parser) resolveVar(variable *models.Var, comment models.Comment, comments []models.Comment) {
+	where := comment.Pos
+	files := p.packageFiles(comment.File)
+	if len(files) == 0 {
+		return
+	}
+
+	decl := p.attachedDecl(comment, comments)
+	if decl != nil && variable.Name != "" && !contains(declNames(decl), variable.Name) {
+		if byName := findVar(files, variable.Name); byName != nil {
+			decl = byName
+		} else {
+			p.report(models.Warningf(models.CodeMismatch, "block for var '%s' is followed by var '%s'", variable.Name, declName(decl)), where)
+			decl = nil
+		}
+	} else if decl == nil && variable.Name != "" {
+		decl = findVar(files, variable.Name)
+	}
+	if decl == nil {
+		if variable.Name != "" {
+			p.report(models.Errorf(models.CodeNoDeclaration, "var '%s' is documented but no matching declaration exists", variable.Name).WithFix("remove the block or correct the name it documents"), where)
+		}
+		return
+	}
+	if variable.Name == "" {
+		variable.Name = declName(decl)
+	}
+
+	// Variables declared with := or without an explicit type have nothing to compare against
+	spec, ok := decl.(*ast.ValueSpec)
+	if !ok || spec.Type == nil {
+		return
+	}
+	actualType := types.ExprString(spec.Type)
+	if variable.Type != "" && !sameType(variable.Type, actualType) {
+		p.report(models.Warningf(models.CodeMismatch, "var '%s' documents type '%s' but the declaration has '%s'", variable.Name, variable.Type, actualType).WithFix(fmt.Sprintf("change the documented type to '%s' or leave it out", actualType)), where)
+	}
+	variable.Type = actualType
+}
+
+// mergeVars lines documented params/fields up with the declared ones by name.
+// The result follows declaration order, keeps the documented descriptions and takes types from the declaration.
+func (p *Parser) mergeVars(documented, declared []models.Var, where models.Position, owner, what string) []models.Var {
+	var merged []models.Var
+	used := make([]bool, len(documented))
+	for i, actual := range declared {
+		match := -1
+		if actual.Name == "" {
+			// Unnamed params can only be matched by position
+			if i < len(documented) {
+				match = i
+			}
+		} else {
+			for j, doc := range documented {
+				if !used[j] && doc.Name == actual.Name {
+					match = j
+					break
+				}
+			}
+		}
+
+		if match == -1 {
+			// Only hold the block to the full list if it documents any at all
+			if len(documented) > 0 {
+				p.report(models.Infof(models.CodeUndocumented, "%s '%s' of %s is not documented", what, actual.Name, owner).WithFix(fmt.Sprintf("add '@%s %s: description'", what, actual.Name)), where)
+			}
+			merged = append(merged, actual)
+			continue
+		}
+
+		used[match] = true
+		doc := documented[match]
+		if doc.Type != "" && !sameType(doc.Type, actual.Type) {
+			p.report(models.Warningf(models.CodeMismatch, "%s documents %s '%s' as '%s' but the declaration has '%s'", owner, what, actual.Name, doc.Type, actual.Type).WithFix(fmt.Sprintf("change the documented type to '%s' or leave it out", actual.Type)), doc.Pos)
+		}
+		merged = append(merged, models.Var{Name: actual.Name, Type: actual.Type, Desc: doc.Desc, Pos: doc.Pos})
+	}
+
+	for j, doc := range documented {
+		if !used[j] {
+			p.report(models.Errorf(models.CodeNoDeclaration, "%s documents %s '%s' which does not exist", owner, what, doc.Name).WithFix("remove the tag"), doc.Pos)
+		}
+	}
+	return merged
+}
+
+// findFuncDecl looks up a func by name, preferring the one declared on the given receiver
+func findFuncDecl(files []*ast.File, name, receiver string) *ast.FuncDecl {
+	var fallback *ast.FuncDecl
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != name {
+				continue
+			}
+			if receiverName(fn) == trimPointer(receiver) {
+				return fn
+			}
+			if fallback == nil {
+				fallback = fn
+			}
+		}
+	}
+	return fallback
+}
+
+func findTypeSpec(files []*ast.File, name string) *ast.TypeSpec {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == name {
+					return ts
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findVar looks for a package-level var/const first, then for variables declared inside function bodies
+func findVar(files []*ast.File, name string) ast.Node {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || (gen.Tok != token.VAR && gen.Tok != token.CONST) {
+				continue
+			}
+			if spec := findValueSpec(gen, name); spec != nil {
+				return spec
+			}
+		}
+	}
+
+	var found ast.Node
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE && contains(declNames(n), name) {
+					found = n
+				}
+			case *ast.DeclStmt:
+				if gen, ok := n.Decl.(*ast.GenDecl); ok {
+					if spec := findValueSpec(gen, name); spec != nil {
+						found = spec
+					}
+				}
+			}
+			return found == nil
+		})
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findValueSpec(gen *ast.GenDecl, name string) *ast.ValueSpec {
+	for _, spec := range gen.Specs {
+		if vs, ok := spec.(*ast.ValueSpec); ok && contains(declNames(vs), name) {
+			return vs
+		}
+	}
+	return nil
+}
+
+// fieldVars flattens a field list so `a, b int` becomes two entries, each positioned at its declaration
+func (p *Parser) fieldVars(list *ast.FieldList) []models.Var {
+	var vars []models.Var
+	if list == nil {
+		return vars
+	}
+	for _, field := range list.List {
+		typeName := types.ExprString(field.Type)
+		if len(field.Names) == 0 {
+			// Unnamed parameter/result, or an embedded struct field which is named after its type
+			name := ""
+			if _, ok := field.Type.(*ast.FuncType); !ok {
+				name = embeddedName(field.Type)
+			}
+			vars = append(vars, models.Var{Name: name, Type: typeName, Pos: p.declPosition(field.Pos())})
+			continue
+		}
+		for _, ident := range field.Names {
+			vars = append(vars, models.Var{Name: ident.Name, Type: typeName, Pos: p.declPosition(ident.Pos())})
+		}
+	}
+	return vars
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+	return ""
+}
+
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	return embeddedName(fn.Recv.List[0].Type)
+}
+
+// receiverType is the type a method is declared on, keeping the '*' of a pointer receiver: 'UserService' or '*UserService'
+func receiverType(fn *ast.FuncDecl) string {
+	name := receiverName(fn)
+	if name == "" {
+		return ""
+	}
+	if _, pointer := fn.Recv.List[0].Type.(*ast.StarExpr); pointer {
+		return "*" + name
+	}
+	return name
+}
+
+func trimPointer(name string) string {
+	return strings.TrimPrefix(strings.TrimSpace(name), "*")
+}
+
+// sameType compares a documented type with a declared one, ignoring formatting differences
+func sameType(documented, actual string) bool {
+	return normalizeType(documented) == normalizeType(actual)
+}
+
+func normalizeType(typeName string) string {
+	typeName = strings.TrimSpace(typeName)
+	if expr, err := goparser.ParseExpr(typeName); err == nil {
+		return types.ExprString(expr)
+	}
+	return strings.Join(strings.Fields(typeName), "")
+}
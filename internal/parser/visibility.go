@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// applyVisibility drops whatever unexported funcs, vars, constants and types the settings leave out of the docs
+func (p *Parser) applyVisibility(pkg *models.Package) {
+	if !p.settings.IncludePrivateTypes {
+		// Methods and constructors of an unexported type go with it
+		pkg.Types = keepExported(pkg.Types, func(_type models.Type) string { return _type.Name })
+		pkg.Interfaces = keepExported(pkg.Interfaces, func(iface models.Interface) string { return iface.Name })
+	}
+	if !p.settings.IncludePrivateFuncs {
+		for i := range pkg.Types {
+			pkg.Types[i].Constructors = keepExported(pkg.Types[i].Constructors, funcName)
+			pkg.Types[i].Methods = keepExported(pkg.Types[i].Methods, funcName)
+		}
+	}
+	if !p.settings.IncludePrivateVars {
+		for i := range pkg.Consts {
+			pkg.Consts[i].Values = keepExported(pkg.Consts[i].Values, func(value models.Const) string { return value.Name })
+		}
+	}
+
+	// Everything in a file is unexported
+	for i := range pkg.Files {
+		if !p.settings.IncludePrivateFuncs {
+			pkg.Files[i].Funcs = nil
+		}
+		if !p.settings.IncludePrivateVars {
+			pkg.Files[i].Vars = nil
+			pkg.Files[i].Consts = nil
+		}
+		if !p.settings.IncludePrivateTypes {
+			pkg.Files[i].Types = nil
+			pkg.Files[i].Interfaces = nil
+		} else if !p.settings.IncludePrivateFuncs {
+			for j := range pkg.Files[i].Types {
+				pkg.Files[i].Types[j].Constructors = keepExported(pkg.Files[i].Types[j].Constructors, funcName)
+				pkg.Files[i].Types[j].Methods = keepExported(pkg.Files[i].Types[j].Methods, funcName)
+			}
+		}
+	}
+}
+
+// keepExported returns the items whose names are exported
+func keepExported[T any](items []T, name func(T) string) []T {
+	var kept []T
+	for _, item := range items {
+		if isExported(name(item)) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func funcName(function models.Func) string {
+	return function.Name
+}
+
+func isExported(name string) bool {
+	return name != "" && unicode.IsUpper(rune(name[0]))
+}
+
+// checkExcludePackages reports every ExcludePackages pattern that isn't a valid glob, so it isn't silently ignored
+func (p *Parser) checkExcludePackages() {
+	for _, pattern := range p.settings.ExcludePackages {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/..."), ""); err != nil {
+			p.report(models.Errorf(models.CodeSettings, "ExcludePackages pattern '%s' is malformed: %v", pattern, err).WithFix("use a glob such as 'example.com/mod/internal/*' or 'example.com/mod/internal/...'"), models.Position{File: "settings.json"})
+		}
+	}
+}
+
+// isExcluded reports whether an import path matches one of the ExcludePackages patterns.
+// Patterns are globs matched against the whole import path, and a pattern ending in '/...' also matches every package below it.
+func (p *Parser) isExcluded(importPath string) bool {
+	for _, pattern := range p.settings.ExcludePackages {
+		if below, ok := strings.CutSuffix(pattern, "/..."); ok {
+			// Match the package itself or any of its parents
+			for dir := importPath; dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+				if matched, _ := path.Match(below, dir); matched {
+					return true
+				}
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, importPath); matched {
+			return true
+		}
+	}
+	return false
+}
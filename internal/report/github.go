@@ -0,0 +1,56 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// writeGitHub writes GitHub Actions workflow commands (::error file=...::message), one per diagnostic
+func writeGitHub(out io.Writer, diagnostics []models.Diagnostic) error {
+	for _, diag := range diagnostics {
+		var properties []string
+		if diag.Pos.File != "" {
+			properties = append(properties, "file="+escapeProperty(reportPath(diag.Pos.File)))
+			if diag.Pos.Line > 0 {
+				properties = append(properties, fmt.Sprintf("line=%d", diag.Pos.Line))
+			}
+			if diag.Pos.Column > 0 {
+				properties = append(properties, fmt.Sprintf("col=%d", diag.Pos.Column))
+			}
+		}
+		properties = append(properties, "title="+escapeProperty(fmt.Sprintf("%s %s", toolName, diag.Code)))
+
+		_, err := fmt.Fprintf(out, "::%s %s::%s\n", githubCommand(diag.Severity), strings.Join(properties, ","), escapeData(messageWithFix(diag)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func githubCommand(severity models.Severity) string {
+	switch severity {
+	case models.SeverityError:
+		return "error"
+	case models.SeverityWarning:
+		return "warning"
+	}
+	return "notice"
+}
+
+// escapeData escapes a workflow command's message, keeping line breaks
+func escapeData(text string) string {
+	text = strings.ReplaceAll(text, "%", "%25")
+	text = strings.ReplaceAll(text, "\r", "%0D")
+	return strings.ReplaceAll(text, "\n", "%0A")
+}
+
+// escapeProperty escapes a workflow command property, which also can't contain ':' or ','
+func escapeProperty(text string) string {
+	text = escapeData(text)
+	text = strings.ReplaceAll(text, ":", "%3A")
+	return strings.ReplaceAll(text, ",", "%2C")
+}
@@ -0,0 +1,102 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes one test case per diagnostic. Errors and warnings are failures, info passes with its message as output.
+// A run with no diagnostics still gets a single passing case, so CI shows the check ran.
+func writeJUnit(out io.Writer, task string, diagnostics []models.Diagnostic) error {
+	suite := junitTestSuite{Name: fmt.Sprintf("%s %s", toolName, task)}
+
+	for _, diag := range diagnostics {
+		file := reportPath(diag.Pos.File)
+		testCase := junitTestCase{
+			Name:      diag.Code,
+			ClassName: file,
+			File:      file,
+			Line:      diag.Pos.Line,
+		}
+		if diag.Pos.File != "" {
+			testCase.Name = fmt.Sprintf("%s %s", diag.Code, diag.Pos)
+		}
+		if testCase.ClassName == "" {
+			testCase.ClassName = toolName
+		}
+
+		if diag.Severity == models.SeverityInfo {
+			testCase.SystemOut = messageWithFix(diag)
+		} else {
+			testCase.Failure = &junitFailure{
+				Message: diag.Message,
+				Type:    string(diag.Severity),
+				Text:    diag.Error() + fixLine(diag),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if len(suite.Cases) == 0 {
+		suite.Cases = append(suite.Cases, junitTestCase{Name: "documentation", ClassName: toolName})
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(junitTestSuites{
+		Name:     toolName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
+
+func fixLine(diag models.Diagnostic) string {
+	if diag.Fix == "" {
+		return ""
+	}
+	return "\nfix: " + diag.Fix
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+const (
+	FormatSARIF  = "sarif"
+	FormatJUnit  = "junit"
+	FormatGitHub = "github"
+)
+
+const toolName = "GoDoc"
+
+// Write saves diagnostics from a task (save or gen) to path in the given format.
+// A path of "-" writes to stdout, which is where GitHub Actions reads annotations from.
+func Write(path, format, task string, diagnostics []models.Diagnostic) error {
+	format, err := ResolveFormat(path, format)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating report file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch format {
+	case FormatSARIF:
+		return writeSARIF(out, diagnostics)
+	case FormatJUnit:
+		return writeJUnit(out, task, diagnostics)
+	}
+	return writeGitHub(out, diagnostics)
+}
+
+// ResolveFormat checks a report format, inferring an empty one from the file name:
+// .sarif/.json is SARIF, .xml is JUnit, and anything else is GitHub annotations
+func ResolveFormat(path, format string) (string, error) {
+	switch format {
+	case FormatSARIF, FormatJUnit, FormatGitHub:
+		return format, nil
+	case "":
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".sarif", ".json":
+			return FormatSARIF, nil
+		case ".xml":
+			return FormatJUnit, nil
+		}
+		return FormatGitHub, nil
+	}
+	return "", fmt.Errorf("unknown report format '%s', expected '%s', '%s' or '%s'", format, FormatSARIF, FormatJUnit, FormatGitHub)
+}
+
+// reportPath turns a diagnostic's file into a clean, slash-separated path relative to the working directory,
+// which is what code scanning and annotations match against the repository
+func reportPath(file string) string {
+	if file == "" {
+		return ""
+	}
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+// messageWithFix appends the suggested fix to a diagnostic's message
+func messageWithFix(diag models.Diagnostic) string {
+	if diag.Fix == "" {
+		return diag.Message
+	}
+	return diag.Message + "\nfix: " + diag.Fix
+}
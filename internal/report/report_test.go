@@ -0,0 +1,212 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// testDiagnostics covers each severity, with and without a position and a fix
+func testDiagnostics() []models.Diagnostic {
+	return []models.Diagnostic{
+		models.Errorf(models.CodeUnknownTag, "unrecognized tag 'bogus'").At(models.Position{File: "db/db.go", Line: 12, Column: 5}).WithFix("use @desc"),
+		models.Warningf(models.CodeMismatch, "block for func 'A' is followed by func 'B'").At(models.Position{File: "db/db.go", Line: 30}),
+		models.Infof(models.CodeUndocumented, "value 'X' is not documented").At(models.Position{File: "types/types.go", Line: 7, Column: 1}),
+		models.Errorf(models.CodeNoPackages, "no packages found"),
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeSARIF(&out, testDiagnostics()); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("output isn't valid JSON: %v\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || log.Schema == "" {
+		t.Errorf("version = %q, schema = %q", log.Version, log.Schema)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != toolName {
+		t.Errorf("driver name = %q", run.Tool.Driver.Name)
+	}
+	if len(run.Results) != 4 {
+		t.Fatalf("got %d results, want 4", len(run.Results))
+	}
+
+	tests := []struct {
+		level  string
+		uri    string
+		line   int
+		column int
+	}{
+		{level: "error", uri: "db/db.go", line: 12, column: 5},
+		{level: "warning", uri: "db/db.go", line: 30},
+		{level: "note", uri: "types/types.go", line: 7, column: 1},
+		{level: "error"},
+	}
+	for i, test := range tests {
+		result := run.Results[i]
+		if result.Level != test.level {
+			t.Errorf("result %d level = %q, want %q", i, result.Level, test.level)
+		}
+		if rule := run.Tool.Driver.Rules[result.RuleIndex]; rule.ID != result.RuleID || rule.ShortDescription.Text == "" {
+			t.Errorf("result %d rule %d = %+v, want %s with a description", i, result.RuleIndex, rule, result.RuleID)
+		}
+		if test.uri == "" {
+			if len(result.Locations) != 0 {
+				t.Errorf("result %d locations = %+v, want none", i, result.Locations)
+			}
+			continue
+		}
+		if len(result.Locations) != 1 {
+			t.Fatalf("result %d has %d locations, want 1", i, len(result.Locations))
+		}
+		location := result.Locations[0].PhysicalLocation
+		if location.ArtifactLocation.URI != test.uri {
+			t.Errorf("result %d uri = %q, want %q", i, location.ArtifactLocation.URI, test.uri)
+		}
+		if location.Region == nil || location.Region.StartLine != test.line || location.Region.StartColumn != test.column {
+			t.Errorf("result %d region = %+v, want line %d column %d", i, location.Region, test.line, test.column)
+		}
+	}
+	if !strings.Contains(run.Results[0].Message.Text, "fix: use @desc") {
+		t.Errorf("message = %q, want the fix included", run.Results[0].Message.Text)
+	}
+	// A column of 0 means unknown, which SARIF leaves out rather than writing
+	if strings.Contains(out.String(), `"startColumn": 0`) {
+		t.Error("unknown column written as 0")
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeJUnit(&out, "save", testDiagnostics()); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("output isn't valid XML: %v\n%s", err, out.String())
+	}
+	if suites.Tests != 4 || suites.Failures != 3 || len(suites.Suites) != 1 {
+		t.Fatalf("tests = %d, failures = %d, suites = %d, want 4, 3 and 1", suites.Tests, suites.Failures, len(suites.Suites))
+	}
+
+	cases := suites.Suites[0].Cases
+	tests := []struct {
+		name    string
+		file    string
+		failure string // Failure type, empty for a passing case
+	}{
+		{name: "GD001 db/db.go:12:5", file: "db/db.go", failure: "error"},
+		{name: "GD021 db/db.go:30:0", file: "db/db.go", failure: "warning"},
+		{name: "GD022 types/types.go:7:1", file: "types/types.go"},
+		{name: "GD040", failure: "error"},
+	}
+	for i, test := range tests {
+		testCase := cases[i]
+		if testCase.Name != test.name || testCase.File != test.file {
+			t.Errorf("case %d = %q in %q, want %q in %q", i, testCase.Name, testCase.File, test.name, test.file)
+		}
+		if test.failure == "" {
+			if testCase.Failure != nil || testCase.SystemOut == "" {
+				t.Errorf("case %d = %+v, want a passing case with output", i, testCase)
+			}
+			continue
+		}
+		if testCase.Failure == nil {
+			t.Fatalf("case %d has no failure", i)
+		}
+		if testCase.Failure.Type != test.failure || testCase.Failure.Message == "" {
+			t.Errorf("case %d failure = %+v, want type %q with a message", i, testCase.Failure, test.failure)
+		}
+	}
+	if !strings.Contains(cases[0].Failure.Text, "fix: use @desc") {
+		t.Errorf("failure text = %q, want the fix included", cases[0].Failure.Text)
+	}
+
+	// A clean run still reports a passing case
+	out.Reset()
+	if err := writeJUnit(&out, "gen", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+	if suites.Tests != 1 || suites.Failures != 0 {
+		t.Errorf("clean run tests = %d, failures = %d, want 1 and 0", suites.Tests, suites.Failures)
+	}
+}
+
+func TestWriteGitHub(t *testing.T) {
+	tests := []struct {
+		name string
+		diag models.Diagnostic
+		want string
+	}{
+		{
+			name: "position and fix",
+			diag: models.Errorf(models.CodeUnknownTag, "unrecognized tag 'bogus'").At(models.Position{File: "db/db.go", Line: 12, Column: 5}).WithFix("use @desc"),
+			want: "::error file=db/db.go,line=12,col=5,title=GoDoc GD001::unrecognized tag 'bogus'%0Afix: use @desc\n",
+		},
+		{
+			name: "no position",
+			diag: models.Warningf(models.CodeNoPackages, "no packages found"),
+			want: "::warning title=GoDoc GD040::no packages found\n",
+		},
+		{
+			name: "escaped message",
+			diag: models.Infof(models.CodeUndocumented, "100%% done\r\nnext line"),
+			want: "::notice title=GoDoc GD022::100%25 done%0D%0Anext line\n",
+		},
+		{
+			name: "escaped properties",
+			diag: models.Errorf(models.CodeUnknownTag, "bad").At(models.Position{File: "a,b:c%.go", Line: 1}),
+			want: "::error file=a%2Cb%3Ac%25.go,line=1,title=GoDoc GD001::bad\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeGitHub(&out, []models.Diagnostic{test.diag}); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.want {
+				t.Errorf("got  %q\nwant %q", out.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		path, format, want string
+	}{
+		{path: "godoc.sarif", want: FormatSARIF},
+		{path: "godoc.json", want: FormatSARIF},
+		{path: "godoc.xml", want: FormatJUnit},
+		{path: "-", want: FormatGitHub},
+		{path: "godoc.xml", format: FormatSARIF, want: FormatSARIF},
+	}
+	for _, test := range tests {
+		got, err := ResolveFormat(test.path, test.format)
+		if err != nil || got != test.want {
+			t.Errorf("ResolveFormat(%q, %q) = %q, %v, want %q", test.path, test.format, got, err, test.want)
+		}
+	}
+	if _, err := ResolveFormat("out", "yaml"); err == nil {
+		t.Error("unknown format wasn't rejected")
+	}
+}
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/ajtroup1/GoDoc/internal/models"
+)
+
+// SARIF 2.1.0, trimmed down to the properties GoDoc fills in
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSARIF(out io.Writer, diagnostics []models.Diagnostic) error {
+	// Only the rules that were hit are listed, in code order
+	var codes []string
+	for _, diag := range diagnostics {
+		if !contains(codes, diag.Code) {
+			codes = append(codes, diag.Code)
+		}
+	}
+	sort.Strings(codes)
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: "https://github.com/ajtroup1/GoDoc",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	for _, code := range codes {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               code,
+			ShortDescription: sarifMessage{Text: models.CodeSummaries[code]},
+		})
+	}
+
+	for _, diag := range diagnostics {
+		result := sarifResult{
+			RuleID:    diag.Code,
+			RuleIndex: indexOf(codes, diag.Code),
+			Level:     sarifLevel(diag.Severity),
+			Message:   sarifMessage{Text: messageWithFix(diag)},
+		}
+		if diag.Pos.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: reportPath(diag.Pos.File)},
+			}}
+			if diag.Pos.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: diag.Pos.Line, StartColumn: diag.Pos.Column}
+			}
+			result.Locations = append(result.Locations, location)
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifLevel(severity models.Severity) string {
+	switch severity {
+	case models.SeverityError:
+		return "error"
+	case models.SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func contains(list []string, value string) bool {
+	return indexOf(list, value) >= 0
+}
+
+func indexOf(list []string, value string) int {
+	for i, item := range list {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
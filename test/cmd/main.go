@@ -29,23 +29,28 @@ import (
 -- TYPE
 @type testType
 @desc This is a test for unexported type names.
-@field field1 (Type): This is here for testing.
-@field field2 (Type2): This is here for testing.
+@field field1 (string): This is here for testing.
+@field field2 (int): This is here for testing.
 */
 
+type testType struct {
+	field1 string
+	field2 int
+}
+
 /***
 -- VAR
 @var ExportedVar
 @desc This is a test variable.
-@type VariableType
+@type int
 */
 
+var ExportedVar int
+
 /***
 -- FUNC
 @func main
 @desc The main function for the entire program. Creates a new handler using 'handler' and Gorilla Mux to listen and serve on port 8080. The example exists for testing purposes.
-@param testParam (int): This is only here for testing.
-@ret (string): This is only here for testing.
 */
 
 func main() {
@@ -2,7 +2,7 @@
 -- FILE
 @file db.go
 @desc Provides functions for establishing a database connection using the MySQL driver.
-@auth John Smith
+@auth John Smith <john@example.com>
 @v 1.0
 @date 01/01/2024
 */
@@ -25,7 +25,7 @@ import (
 /***
 -- FUNC
 @func NewConnection
-@desc Creates a new connection to the MySQL database using the provided Data Source Name (DSN).
+@desc Creates a new connection to the MySQL database using the provided Data Source Name (DSN), e.g. user:password@tcp(127.0.0.1:3306)/mydatabase. Install the driver with `go get github.com/go-sql-driver/mysql@latest` and ask \@dba-team for credentials.
 @return (*sql.DB) Database connection instance.
 @return (error) Any error encountered while opening the database connection.
 */
@@ -30,8 +30,6 @@ import (
 @type UserHandler
 @desc Handler for user-related HTTP requests, utilizing the user service to handle business logic.
 @field service (UserService): Service for managing user-related operations.
-@field service2 (Type2): This is here for testing.
-@field service3 (Type3): This is here for testing.
 */
 
 /***
@@ -41,6 +39,8 @@ import (
 @desc This is a test var for this pkg.
 */
 
+var ExampleVar int
+
 type UserHandler struct {
 	service service.UserService
 }
@@ -92,6 +92,8 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 @desc This is a test var for this pkg.
 */
 
+var exampleVar int
+
 func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
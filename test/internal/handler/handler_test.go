@@ -42,6 +42,7 @@ func createTestHandler() *UserHandler {
 	return &UserHandler{service: mockService}
 }
 
+// TestGetAllUsers checks that every stored user is returned as JSON.
 func TestGetAllUsers(t *testing.T) {
 	handler := createTestHandler()
 	users := []model.User{
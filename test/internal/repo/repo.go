@@ -50,7 +50,16 @@ func NewUserRepository(dbConn *sql.DB) *UserRepository {
 /***
 -- FUNC
 @func GetAllUsers
-@desc Retrieves all users from the database.
+@desc Retrieves all users from the database,
+in the order the database returns them.
+
+Each row is scanned into a `model.User`:
+- `id` and `name` are required
+- `email` may be empty
+
+Example:
+
+    users, err := repo.GetAllUsers()
 @return ([]model.User) Slice of user models representing all users in the database.
 @return (error) Any error encountered during the query execution.
 @rec UserRepository
@@ -23,6 +23,23 @@ import (
 	"myapp/internal/repository"
 )
 
+/***
+-- INTERFACE
+@desc Reads users from wherever they are stored.
+@method GetAllUsers: Retrieves every stored user.
+@return Every user, in storage order.
+@return Any error encountered while reading.
+@method GetUserByID: Retrieves a single user.
+@param id: ID of the user to retrieve.
+@return The user with the given ID.
+@return An error if the user doesn't exist.
+*/
+
+type UserStore interface {
+	GetAllUsers() ([]model.User, error)
+	GetUserByID(id int) (model.User, error)
+}
+
 type UserService struct {
 	repo repository.UserRepository
 }
@@ -31,3 +31,59 @@ type User struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
+
+/***
+ * -- TYPE
+ * @type Role
+ * @desc Names what a user is allowed to do. Written with Javadoc-style
+ *       decoration, which GoDoc strips.
+ */
+
+type Role string
+
+/**
+ * @brief Creates a user with the given details.
+ *
+ * The user starts without a role; assign one before saving it.
+ *
+ * @param id The user's unique identifier.
+ * @param[in] name The user's name.
+ * @param email The user's email address.
+ * @returns The new user.
+ */
+func NewUser(id int, name, email string) User {
+	return User{ID: id, Name: name, Email: email}
+}
+
+/***
+-- TYPE
+@desc Where a user's account stands.
+*/
+
+type Status int
+
+/***
+-- ENUM
+@desc Every status an account can be in.
+@value StatusActive: The user can sign in.
+@value StatusSuspended: The user is locked out until an admin restores the account.
+*/
+
+const (
+	StatusActive Status = iota + 1
+	StatusSuspended
+	StatusDeleted // The account is gone for good.
+	_
+	StatusUnknown = StatusActive << 4
+)
+
+/***
+-- CONST
+@const limits
+@desc Limits on user fields.
+*/
+
+const (
+	maxNameLength  = 64
+	maxEmailLength = maxNameLength * 4
+)
@@ -34,12 +34,14 @@ func retrieveOrCreateSettings() (models.Settings, error) {
 	// Check if settings.json exists
 	if _, err := os.Stat(settingsFile); errors.Is(err, os.ErrNotExist) {
 		// File doesn't exist, create default settings
+		tocDepth := 2
 		defaultSettings := models.Settings{
 			ProjectName:  "",
 			ProjectDesc:  "",
 			ProjectPath:  "./",
 			DocGenPath:   "./",
-			DocGenFormat: "markdown",
+			DocGenFormat: models.Formats{"markdown"},
+			TOCDepth:     &tocDepth,
 		}
 
 		// Save default settings to a new JSON file